@@ -0,0 +1,91 @@
+// Command config provides tools for configuration specification files.
+//
+// Usage:
+//
+//	config sample SPEC-FILE
+//	config gen [-package NAME] [-type NAME] [-o FILE] SPEC-FILE
+//
+// Subcommands:
+//
+//	sample  print annotated sample configuration file for the spec
+//	gen     generate Go code with typed accessors for the spec
+//
+// Gen subcommand is intended to be used with go generate:
+//
+//	//go:generate go run github.com/vchimishuk/config/cmd/config gen -o config_gen.go app.spec
+//
+// When -package flag is not given, GOPACKAGE environment variable set by
+// go generate is used.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/vchimishuk/config"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: config sample SPEC-FILE\n")
+	fmt.Fprintf(os.Stderr, "       config gen [-package NAME] [-type NAME] "+
+		"[-o FILE] SPEC-FILE\n")
+	os.Exit(2)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "config: %s\n", err)
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "sample":
+		sample(os.Args[2:])
+	case "gen":
+		gen(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func sample(args []string) {
+	if len(args) != 1 {
+		usage()
+	}
+	spec, err := config.LoadSpecFile(args[0])
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Print(config.Sample(spec))
+}
+
+func gen(args []string) {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	fs.Usage = usage
+	pkg := fs.String("package", os.Getenv("GOPACKAGE"), "package name")
+	typ := fs.String("type", "Config", "configuration type name")
+	out := fs.String("o", "", "output file (default: standard output)")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *pkg == "" {
+		usage()
+	}
+
+	spec, err := config.LoadSpecFile(fs.Arg(0))
+	if err != nil {
+		fatal(err)
+	}
+	src, err := config.GenerateGo(spec, *pkg, *typ)
+	if err != nil {
+		fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+	} else if err := os.WriteFile(*out, src, 0644); err != nil {
+		fatal(err)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"time"
 )
@@ -9,148 +10,137 @@ type Property struct {
 	Type  Type
 	Name  string
 	Value any
+	// Origin of the property: name of the file property is read from
+	// (see Source option) or one of SourceDefault, SourceEnv and
+	// SourceFlag. Empty if configuration is parsed from a string
+	// without Source option.
+	Source string
+	// Position of the property name in the source file. Zero if
+	// property is not read from configuration file.
+	Pos Position
 }
 
 type Block struct {
 	Name       string
 	Properties []*Property
 	Blocks     []*Block
+	// Block labels, see BlockSpec.Labels.
+	Labels []string
+	// Origin of the block, see Property.Source.
+	Source string
+	// Position of the block name in the source file, see Property.Pos.
+	Pos Position
 }
 
 func (b *Block) Has(name string) bool {
-	return property(b.Properties, name) != nil || b.Block(name) != nil
+	return b.Property(name) != nil || b.Block(name) != nil
 }
 
-func (b *Block) Any(name string) any {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
+// Property returns the first property by name or nil if no such property
+// found.
+func (b *Block) Property(name string) *Property {
+	return property(b.Properties, name)
+}
 
-	return p.Value
+// AllProperties returns all properties with the given name.
+func (b *Block) AllProperties(name string) []*Property {
+	return properties(b.Properties, name)
+}
+
+func (b *Block) Any(name string) any {
+	return must(get[any](b, name))
 }
 
 func (b *Block) AnyOr(name string, defvalue any) any {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value
+func (b *Block) LookupAny(name string) (any, error) {
+	return get[any](b, name)
 }
 
 func (b *Block) Anys(name string) []any {
-	return values[any](properties(b.Properties, name))
+	return must(getAll[any](b, name))
 }
 
 func (b *Block) Bool(name string) bool {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(bool)
+	return must(get[bool](b, name))
 }
 
 func (b *Block) BoolOr(name string, defvalue bool) bool {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value.(bool)
+func (b *Block) LookupBool(name string) (bool, error) {
+	return get[bool](b, name)
 }
 
 func (b *Block) Bools(name string) []bool {
-	return values[bool](properties(b.Properties, name))
+	return must(getAll[bool](b, name))
 }
 
 func (b *Block) Duration(name string) time.Duration {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(time.Duration)
+	return must(get[time.Duration](b, name))
 }
 
 func (b *Block) DurationOr(name string, defvalue time.Duration) time.Duration {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value.(time.Duration)
+func (b *Block) LookupDuration(name string) (time.Duration, error) {
+	return get[time.Duration](b, name)
 }
 
 func (b *Block) Durations(name string) []time.Duration {
-	return values[time.Duration](properties(b.Properties, name))
+	return must(getAll[time.Duration](b, name))
 }
 
 func (b *Block) Int(name string) int {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(int)
+	return must(get[int](b, name))
 }
 
 func (b *Block) IntOr(name string, defvalue int) int {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value.(int)
+func (b *Block) LookupInt(name string) (int, error) {
+	return get[int](b, name)
 }
 
 func (b *Block) Ints(name string) []int {
-	return values[int](properties(b.Properties, name))
+	return must(getAll[int](b, name))
 }
 
 func (b *Block) String(name string) string {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(string)
+	return must(get[string](b, name))
 }
 
 func (b *Block) StringOr(name string, defvalue string) string {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value.(string)
+func (b *Block) LookupString(name string) (string, error) {
+	return get[string](b, name)
 }
 
 func (b *Block) Strings(name string) []string {
-	return values[string](properties(b.Properties, name))
+	return must(getAll[string](b, name))
 }
 
 func (b *Block) StringList(name string) []string {
-	p := property(b.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.([]string)
+	return must(get[[]string](b, name))
 }
 
 func (b *Block) StringListOr(name string, defvalue []string) []string {
-	p := property(b.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(b, name, defvalue))
+}
 
-	return p.Value.([]string)
+func (b *Block) LookupStringList(name string) ([]string, error) {
+	return get[[]string](b, name)
 }
 
 func (b *Block) StringLists(name string) [][]string {
-	return values[[]string](properties(b.Properties, name))
+	return must(getAll[[]string](b, name))
 }
 
 // Block returns block by name or nil if no such block found.
@@ -164,145 +154,153 @@ func (b *Block) Block(name string) *Block {
 	return nil
 }
 
+// BlockLabeled returns the first nested block with the given name and
+// labels or nil if no such block found.
+func (b *Block) BlockLabeled(name string, labels ...string) *Block {
+	for _, b := range b.Blocks {
+		if b.Name == name && equalLabels(b.Labels, labels) {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// AllBlocks returns all nested blocks with the given name.
+func (b *Block) AllBlocks(name string) []*Block {
+	return blocks(b.Blocks, func(b *Block) bool {
+		return b.Name == name
+	})
+}
+
+// BlocksMatching returns all nested blocks which names match the
+// star-pattern, see MatchName.
+func (b *Block) BlocksMatching(pattern string) []*Block {
+	return blocks(b.Blocks, func(b *Block) bool {
+		return MatchName(b.Name, pattern)
+	})
+}
+
+// BlockMap returns nested blocks matching the star-pattern keyed by
+// block name. Only the first block is returned for repeated names.
+func (b *Block) BlockMap(pattern string) map[string]*Block {
+	return blockMap(b.BlocksMatching(pattern))
+}
+
 type Config struct {
 	Properties []*Property
 	Blocks     []*Block
 }
 
 func (c *Config) Has(name string) bool {
-	return property(c.Properties, name) != nil || c.Block(name) != nil
+	return c.Property(name) != nil || c.Block(name) != nil
 }
 
-func (c *Config) Any(name string) any {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
+// Property returns the first property by name or nil if no such property
+// found.
+func (c *Config) Property(name string) *Property {
+	return property(c.Properties, name)
+}
+
+// AllProperties returns all properties with the given name.
+func (c *Config) AllProperties(name string) []*Property {
+	return properties(c.Properties, name)
+}
 
-	return p.Value
+func (c *Config) Any(name string) any {
+	return must(get[any](c, name))
 }
 
 func (c *Config) AnyOr(name string, defvalue any) any {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value
+func (c *Config) LookupAny(name string) (any, error) {
+	return get[any](c, name)
 }
 
 func (c *Config) Anys(name string) []any {
-	return values[any](properties(c.Properties, name))
+	return must(getAll[any](c, name))
 }
 
 func (c *Config) Bool(name string) bool {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(bool)
+	return must(get[bool](c, name))
 }
 
 func (c *Config) BoolOr(name string, defvalue bool) bool {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value.(bool)
+func (c *Config) LookupBool(name string) (bool, error) {
+	return get[bool](c, name)
 }
 
 func (c *Config) Bools(name string) []bool {
-	return values[bool](properties(c.Properties, name))
+	return must(getAll[bool](c, name))
 }
 
 func (c *Config) Duration(name string) time.Duration {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(time.Duration)
+	return must(get[time.Duration](c, name))
 }
 
 func (c *Config) DurationOr(name string, defvalue time.Duration) time.Duration {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value.(time.Duration)
+func (c *Config) LookupDuration(name string) (time.Duration, error) {
+	return get[time.Duration](c, name)
 }
 
 func (c *Config) Durations(name string) []time.Duration {
-	return values[time.Duration](properties(c.Properties, name))
+	return must(getAll[time.Duration](c, name))
 }
 
 func (c *Config) Int(name string) int {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(int)
+	return must(get[int](c, name))
 }
 
 func (c *Config) IntOr(name string, defvalue int) int {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value.(int)
+func (c *Config) LookupInt(name string) (int, error) {
+	return get[int](c, name)
 }
 
 func (c *Config) Ints(name string) []int {
-	return values[int](properties(c.Properties, name))
+	return must(getAll[int](c, name))
 }
 
 func (c *Config) String(name string) string {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.(string)
+	return must(get[string](c, name))
 }
 
 func (c *Config) StringOr(name string, defvalue string) string {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value.(string)
+func (c *Config) LookupString(name string) (string, error) {
+	return get[string](c, name)
 }
 
 func (c *Config) Strings(name string) []string {
-	return values[string](properties(c.Properties, name))
+	return must(getAll[string](c, name))
 }
 
 func (c *Config) StringList(name string) []string {
-	p := property(c.Properties, name)
-	if p == nil {
-		panic(fmt.Sprintf("`%s` property is not defined", name))
-	}
-
-	return p.Value.([]string)
+	return must(get[[]string](c, name))
 }
 
 func (c *Config) StringListOr(name string, defvalue []string) []string {
-	p := property(c.Properties, name)
-	if p == nil {
-		return defvalue
-	}
+	return must(getOr(c, name, defvalue))
+}
 
-	return p.Value.([]string)
+func (c *Config) LookupStringList(name string) ([]string, error) {
+	return get[[]string](c, name)
 }
 
 func (c *Config) StringLists(name string) [][]string {
-	return values[[]string](properties(c.Properties, name))
+	return must(getAll[[]string](c, name))
 }
 
 // Block returns block by name or nil if no such block found.
@@ -316,13 +314,172 @@ func (c *Config) Block(name string) *Block {
 	return nil
 }
 
-func values[T any](props []*Property) []T {
+// BlockLabeled returns the first nested block with the given name and
+// labels or nil if no such block found.
+func (c *Config) BlockLabeled(name string, labels ...string) *Block {
+	for _, b := range c.Blocks {
+		if b.Name == name && equalLabels(b.Labels, labels) {
+			return b
+		}
+	}
+
+	return nil
+}
+
+// AllBlocks returns all nested blocks with the given name.
+func (c *Config) AllBlocks(name string) []*Block {
+	return blocks(c.Blocks, func(b *Block) bool {
+		return b.Name == name
+	})
+}
+
+// BlocksMatching returns all nested blocks which names match the
+// star-pattern, see MatchName.
+func (c *Config) BlocksMatching(pattern string) []*Block {
+	return blocks(c.Blocks, func(b *Block) bool {
+		return MatchName(b.Name, pattern)
+	})
+}
+
+// BlockMap returns nested blocks matching the star-pattern keyed by
+// block name. Only the first block is returned for repeated names.
+func (c *Config) BlockMap(pattern string) map[string]*Block {
+	return blockMap(c.BlocksMatching(pattern))
+}
+
+var (
+	// ErrNotDefined is returned when requested property is not defined.
+	ErrNotDefined = errors.New("property is not defined")
+	// ErrType is returned when property value has different type.
+	ErrType = errors.New("property has unexpected type")
+)
+
+// PropertyError describes failed property access.
+type PropertyError struct {
+	Name string
+	// ErrNotDefined or ErrType.
+	Err error
+}
+
+func (e *PropertyError) Error() string {
+	return fmt.Sprintf("`%s` %s", e.Name, e.Err)
+}
+
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+// Node is a common interface of Config and Block which provides access
+// to properties and nested blocks.
+type Node interface {
+	Has(name string) bool
+	Property(name string) *Property
+	AllProperties(name string) []*Property
+	Block(name string) *Block
+	BlockLabeled(name string, labels ...string) *Block
+	AllBlocks(name string) []*Block
+	BlocksMatching(pattern string) []*Block
+	BlockMap(pattern string) map[string]*Block
+}
+
+// Get returns value of the first property addressed by the path. Path
+// is a property name optionally prefixed with names of nested blocks
+// separated with dots or slashes, e.g. "server.tls.cert". Repeated blocks
+// and properties can be selected by zero-based index, e.g.
+// "upstream[2].host", labeled blocks are selected by quoted labels, e.g.
+// `upstream["backend-1"].host`, and star in a path element matches any
+// sequence of characters, e.g. "sd*.size". Names with dots, slashes or
+// stars are quoted to be taken literally, e.g. `db."pool.size"`, though
+// unquoted path which does not address nested property is taken as the
+// property name too. Error wrapping ErrNotDefined, ErrType or
+// ErrInvalidPath is returned if property is not defined, has value of
+// other type or path is malformed.
+func Get[T any](n Node, path string) (T, error) {
+	ps, err := resolve(n, path)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return first[T](ps, path)
+}
+
+// GetOr is like Get but returns defvalue if property is not defined.
+func GetOr[T any](n Node, path string, defvalue T) (T, error) {
+	v, err := Get[T](n, path)
+	if errors.Is(err, ErrNotDefined) {
+		return defvalue, nil
+	}
+
+	return v, err
+}
+
+// GetAll returns values of all properties addressed by the path.
+// See Get for the path syntax.
+func GetAll[T any](n Node, path string) ([]T, error) {
+	ps, err := resolve(n, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return all[T](ps, path)
+}
+
+// get, getOr and getAll are like Get, GetOr and GetAll but take exact
+// property name instead of the path. They back typed accessors like
+// Int and IntOr, which address properties by name only.
+func get[T any](n Node, name string) (T, error) {
+	return first[T](n.AllProperties(name), name)
+}
+
+func getOr[T any](n Node, name string, defvalue T) (T, error) {
+	v, err := get[T](n, name)
+	if errors.Is(err, ErrNotDefined) {
+		return defvalue, nil
+	}
+
+	return v, err
+}
+
+func getAll[T any](n Node, name string) ([]T, error) {
+	return all[T](n.AllProperties(name), name)
+}
+
+// first returns value of the first property.
+func first[T any](ps []*Property, name string) (T, error) {
+	var zero T
+
+	if len(ps) == 0 {
+		return zero, &PropertyError{name, ErrNotDefined}
+	}
+	v, ok := ps[0].Value.(T)
+	if !ok {
+		return zero, &PropertyError{name, ErrType}
+	}
+
+	return v, nil
+}
+
+// all returns values of all properties.
+func all[T any](ps []*Property, name string) ([]T, error) {
 	var vs []T
-	for _, p := range props {
-		vs = append(vs, p.Value.(T))
+	for _, p := range ps {
+		v, ok := p.Value.(T)
+		if !ok {
+			return nil, &PropertyError{name, ErrType}
+		}
+		vs = append(vs, v)
 	}
 
-	return vs
+	return vs, nil
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
 }
 
 func property(props []*Property, name string) *Property {
@@ -345,3 +502,27 @@ func properties(props []*Property, name string) []*Property {
 
 	return ps
 }
+
+func blocks(bs []*Block, match func(b *Block) bool) []*Block {
+	var res []*Block
+
+	for _, b := range bs {
+		if match(b) {
+			res = append(res, b)
+		}
+	}
+
+	return res
+}
+
+func blockMap(bs []*Block) map[string]*Block {
+	m := make(map[string]*Block, len(bs))
+
+	for _, b := range bs {
+		if _, ok := m[b.Name]; !ok {
+			m[b.Name] = b
+		}
+	}
+
+	return m
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -8,9 +9,9 @@ import (
 func TestConfigProperty(t *testing.T) {
 	cfg := &Config{
 		[]*Property{
-			&Property{TypeInt, "foo", 123},
-			&Property{TypeString, "bar", "value"},
-			&Property{TypeStringList, "baz", []string{"foo", "bar"}},
+			&Property{Type: TypeInt, Name: "foo", Value: 123},
+			&Property{Type: TypeString, Name: "bar", Value: "value"},
+			&Property{Type: TypeStringList, Name: "baz", Value: []string{"foo", "bar"}},
 		},
 		nil,
 	}
@@ -50,19 +51,19 @@ func TestConfigBlock(t *testing.T) {
 		nil,
 		[]*Block{
 			&Block{
-				"foo",
-				[]*Property{
-					&Property{TypeInt, "foo-foo", 1},
-					&Property{TypeInt, "foo-bar", 2},
+				Name: "foo",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "foo-foo", Value: 1},
+					&Property{Type: TypeInt, Name: "foo-bar", Value: 2},
 				},
-				[]*Block{
+				Blocks: []*Block{
 					&Block{
-						"bar",
-						[]*Property{
-							&Property{TypeInt, "bar-foo", 3},
-							&Property{TypeInt, "bar-bar", 4},
+						Name: "bar",
+						Properties: []*Property{
+							&Property{Type: TypeInt, Name: "bar-foo", Value: 3},
+							&Property{Type: TypeInt, Name: "bar-bar", Value: 4},
 						},
-						nil,
+						Blocks: nil,
 					},
 				},
 			},
@@ -91,3 +92,129 @@ func TestConfigBlock(t *testing.T) {
 		t.Fatal()
 	}
 }
+
+func TestConfigLookup(t *testing.T) {
+	cfg := &Config{
+		[]*Property{
+			&Property{Type: TypeInt, Name: "foo", Value: 123},
+		},
+		[]*Block{
+			&Block{
+				Name: "bar",
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "baz", Value: "value"},
+				},
+				Blocks: nil,
+			},
+		},
+	}
+
+	i, err := cfg.LookupInt("foo")
+	if err != nil || i != 123 {
+		t.Fatal(i, err)
+	}
+	_, err = cfg.LookupInt("qux")
+	if !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+	if err.Error() != "`qux` property is not defined" {
+		t.Fatal(err)
+	}
+	_, err = cfg.LookupString("foo")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+	var perr *PropertyError
+	if !errors.As(err, &perr) || perr.Name != "foo" {
+		t.Fatal(err)
+	}
+
+	s, err := cfg.Block("bar").LookupString("baz")
+	if err != nil || s != "value" {
+		t.Fatal(s, err)
+	}
+	_, err = cfg.Block("bar").LookupDuration("baz")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	cfg := &Config{
+		[]*Property{
+			&Property{Type: TypeInt, Name: "foo", Value: 123},
+			&Property{Type: TypeString, Name: "tag", Value: "a"},
+			&Property{Type: TypeString, Name: "tag", Value: "b"},
+		},
+		[]*Block{
+			&Block{
+				Name: "bar",
+				Properties: []*Property{
+					&Property{Type: TypeBool, Name: "baz", Value: true},
+				},
+				Blocks: nil,
+			},
+		},
+	}
+
+	i, err := Get[int](cfg, "foo")
+	if err != nil || i != 123 {
+		t.Fatal(i, err)
+	}
+	_, err = Get[string](cfg, "foo")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+	b, err := Get[bool](cfg.Block("bar"), "baz")
+	if err != nil || !b {
+		t.Fatal(b, err)
+	}
+
+	i, err = GetOr(cfg, "qux", 1)
+	if err != nil || i != 1 {
+		t.Fatal(i, err)
+	}
+	_, err = GetOr(cfg, "foo", "")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+
+	tags, err := GetAll[string](cfg, "tag")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, []string{"a", "b"}, tags)
+	tags, err = GetAll[string](cfg, "qux")
+	if err != nil || tags != nil {
+		t.Fatal(tags, err)
+	}
+	_, err = GetAll[int](cfg, "tag")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+
+	var n Node = cfg.Block("bar")
+	if !n.Has("baz") || n.Property("baz").Value != true {
+		t.Fatal(n)
+	}
+}
+
+func TestConfigBlocks(t *testing.T) {
+	sda := &Block{Name: "sda", Properties: nil, Blocks: nil}
+	sdb := &Block{Name: "sdb", Properties: nil, Blocks: nil}
+	sdb2 := &Block{Name: "sdb", Properties: nil, Blocks: nil}
+	up1 := &Block{Name: "upstream", Properties: nil, Blocks: nil}
+	up2 := &Block{Name: "upstream", Properties: nil, Blocks: nil}
+	cfg := &Config{nil, []*Block{sda, up1, sdb, up2, sdb2}}
+
+	assert(t, []*Block{up1, up2}, cfg.AllBlocks("upstream"))
+	assert(t, []*Block(nil), cfg.AllBlocks("foo"))
+	assert(t, []*Block{sda, sdb, sdb2}, cfg.BlocksMatching("sd*"))
+	assert(t, map[string]*Block{"sda": sda, "sdb": sdb},
+		cfg.BlockMap("sd*"))
+
+	b := &Block{Name: "root", Properties: nil, Blocks: []*Block{sda, up1}}
+	assert(t, []*Block{up1}, b.AllBlocks("upstream"))
+	assert(t, []*Block{sda}, b.BlocksMatching("sd*"))
+	assert(t, map[string]*Block{}, b.BlockMap("hd*"))
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// ChangeKind is a kind of the configuration change.
+type ChangeKind int
+
+const (
+	// Property or block is defined in the new configuration only.
+	Added ChangeKind = iota
+	// Property or block is defined in the old configuration only.
+	Removed
+	// Property value differs.
+	Changed
+)
+
+var changeKindNames = map[ChangeKind]string{
+	Added:   "added",
+	Removed: "removed",
+	Changed: "changed",
+}
+
+func (k ChangeKind) String() string {
+	if n, ok := changeKindNames[k]; ok {
+		return n
+	}
+
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// Change describes difference of a single property or block between two
+// configurations.
+type Change struct {
+	Kind ChangeKind
+	// Path of the property or block in Get syntax, like "server.port"
+	// or `upstream["api"]`. Repeated blocks with the same name and
+	// labels are distinguished by index, like "hook[1]". Names which
+	// contain path syntax characters are quoted, so port of the db
+	// block has "db.port" path while "db.port" property has
+	// `"db.port"` path.
+	Path string
+	// Old and new property values. Nil for blocks and for the missing
+	// side of added or removed property. Values of repeated property
+	// are given as []any.
+	Old any
+	New any
+}
+
+// Diff returns changes which turn the old configuration into the new
+// one. Properties of added and removed blocks are reported too, so
+// every change of a property is reported by its path.
+func Diff(old *Config, new *Config) []Change {
+	var o, n Block
+	if old != nil {
+		o = Block{Properties: old.Properties, Blocks: old.Blocks}
+	}
+	if new != nil {
+		n = Block{Properties: new.Properties, Blocks: new.Blocks}
+	}
+
+	return diffBlock(nil, "", &o, &n)
+}
+
+func diffBlock(changes []Change, path string, old *Block,
+	new *Block) []Change {
+
+	var names []string
+	seen := map[string]bool{}
+	for _, ps := range [][]*Property{old.Properties, new.Properties} {
+		for _, p := range ps {
+			if !seen[p.Name] {
+				seen[p.Name] = true
+				names = append(names, p.Name)
+			}
+		}
+	}
+	for _, name := range names {
+		ov := propertyValue(properties(old.Properties, name))
+		nv := propertyValue(properties(new.Properties, name))
+		p := joinPath(path, pathName(name))
+		if ov == nil {
+			changes = append(changes, Change{Added, p, nil, nv})
+		} else if nv == nil {
+			changes = append(changes, Change{Removed, p, ov, nil})
+		} else if !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, Change{Changed, p, ov, nv})
+		}
+	}
+
+	// Blocks repeated in any of configurations are indexed in both.
+	multi := map[string]bool{}
+	for _, bs := range [][]*Block{old.Blocks, new.Blocks} {
+		count := map[string]int{}
+		for _, b := range bs {
+			k := blockBase(b)
+			count[k]++
+			if count[k] > 1 {
+				multi[k] = true
+			}
+		}
+	}
+	oks, obs := blockKeys(old.Blocks, multi)
+	nks, nbs := blockKeys(new.Blocks, multi)
+	empty := &Block{}
+	for _, k := range oks {
+		p := joinPath(path, k)
+		if nb, ok := nbs[k]; ok {
+			changes = diffBlock(changes, p, obs[k], nb)
+		} else {
+			changes = append(changes, Change{Kind: Removed, Path: p})
+			changes = diffBlock(changes, p, obs[k], empty)
+		}
+	}
+	for _, k := range nks {
+		if _, ok := obs[k]; !ok {
+			p := joinPath(path, k)
+			changes = append(changes, Change{Kind: Added, Path: p})
+			changes = diffBlock(changes, p, empty, nbs[k])
+		}
+	}
+
+	return changes
+}
+
+// propertyValue returns value of the property, or all values as []any
+// for repeated property, or nil if property is not defined.
+func propertyValue(ps []*Property) any {
+	switch len(ps) {
+	case 0:
+		return nil
+	case 1:
+		return ps[0].Value
+	default:
+		var vs []any
+		for _, p := range ps {
+			vs = append(vs, p.Value)
+		}
+		return vs
+	}
+}
+
+// blockBase returns path element of the block without index.
+func blockBase(b *Block) string {
+	s := pathName(b.Name)
+	for _, l := range b.Labels {
+		s += "[" + strconv.Quote(l) + "]"
+	}
+
+	return s
+}
+
+// blockKeys returns path elements of the blocks in order and blocks
+// keyed by them. Blocks which path elements are in multi set are indexed.
+func blockKeys(blocks []*Block,
+	multi map[string]bool) ([]string, map[string]*Block) {
+
+	var keys []string
+	m := map[string]*Block{}
+	idx := map[string]int{}
+	for _, b := range blocks {
+		k := blockBase(b)
+		if multi[k] {
+			i := idx[k]
+			idx[k]++
+			k += "[" + strconv.Itoa(i) + "]"
+		}
+		keys = append(keys, k)
+		m[k] = b
+	}
+
+	return keys, m
+}
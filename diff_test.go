@@ -0,0 +1,125 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port"},
+			&PropertySpec{Type: TypeString, Name: "tag", Repeat: true},
+			&PropertySpec{Type: TypeBool, Name: "debug"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "db",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+				},
+			},
+			&BlockSpec{
+				Name:   "upstream",
+				Labels: []string{"name"},
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+				},
+			},
+			&BlockSpec{
+				Name:   "hook",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "cmd"},
+				},
+			},
+		},
+	}
+	old, err := Parse(spec, `
+                port = 80
+                tag = "a"
+                debug = true
+                db { host = "a" }
+                upstream "x" { host = "x" }
+                hook { cmd = "one" }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := Parse(spec, `
+                port = 8080
+                tag = "a"
+                tag = "b"
+                db { host = "a" }
+                upstream "y" { host = "y" }
+                hook { cmd = "one" }
+                hook { cmd = "two" }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, []Change{
+		Change{Changed, "port", 80, 8080},
+		Change{Changed, "tag", "a", []any{"a", "b"}},
+		Change{Removed, "debug", true, nil},
+		Change{Kind: Removed, Path: `upstream["x"]`},
+		Change{Removed, `upstream["x"].host`, "x", nil},
+		Change{Kind: Added, Path: `upstream["y"]`},
+		Change{Added, `upstream["y"].host`, nil, "y"},
+		Change{Kind: Added, Path: "hook[1]"},
+		Change{Added, "hook[1].cmd", nil, "two"},
+	}, Diff(old, new))
+	assert(t, []Change(nil), Diff(old, old))
+	assert(t, "changed", Changed.String())
+
+	// Every change path is a valid lookup path.
+	for _, c := range Diff(nil, new) {
+		if c.New == nil {
+			continue
+		}
+		v, err := Get[any](new, c.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := c.New.([]any); !ok {
+			assert(t, c.New, v)
+		}
+	}
+}
+
+func TestDiffQuotedPath(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "db.port"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "db",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "port"},
+				},
+			},
+		},
+	}
+	cfg, err := Parse(spec, `
+                db.port = 1
+                db { port = 2 }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := Diff(nil, cfg)
+	assert(t, []Change{
+		Change{Added, `"db.port"`, nil, 1},
+		Change{Kind: Added, Path: "db"},
+		Change{Added, "db.port", nil, 2},
+	}, changes)
+	for _, c := range changes {
+		if c.New != nil {
+			assert(t, c.New, must(Get[any](cfg, c.Path)))
+		}
+	}
+	assert(t, false, matchPath(changes[0].Path, "db.*"))
+	assert(t, true, matchPath(changes[2].Path, "db.*"))
+}
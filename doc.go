@@ -0,0 +1,254 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Markdown generates reference documentation for all properties and
+// blocks described by the specification in Markdown format. Every block
+// is documented in its own section named after the full block path.
+func Markdown(spec *Spec, title string) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n", title)
+	markdownBlock(&sb, "", 1, spec.Properties, spec.Blocks)
+
+	return sb.String()
+}
+
+func markdownBlock(sb *strings.Builder, path string, level int,
+	props []*PropertySpec, blocks []*BlockSpec) {
+
+	if len(props) > 0 {
+		sb.WriteString("\n| Name | Type | Required | Repeat | Default | Description |\n")
+		sb.WriteString("|------|------|----------|--------|---------|-------------|\n")
+		for _, p := range props {
+			def := ""
+			if p.Default != nil {
+				def = "`" + formatValue(p.Type, p.Default) + "`"
+			}
+			desc := p.Description
+			if p.Deprecated != "" {
+				desc = strings.TrimSpace("Deprecated: " +
+					p.Deprecated + " " + desc)
+			}
+			if len(p.Aliases) > 0 {
+				desc = strings.TrimSpace(desc + " Aliases: `" +
+					strings.Join(p.Aliases, "`, `") + "`.")
+			}
+			if len(p.Enum) > 0 {
+				desc = strings.TrimSpace(desc + " Values: `" +
+					strings.Join(p.Enum, "`, `") + "`.")
+			}
+			if p.Example != "" {
+				desc = strings.TrimSpace(desc + " Example: `" +
+					p.Example + "`.")
+			}
+			fmt.Fprintf(sb, "| `%s` | %s | %s | %s | %s | %s |\n",
+				p.Name, p.Type, yesNo(p.Require), yesNo(p.Repeat),
+				def, markdownCell(desc))
+		}
+	}
+
+	for _, b := range blocks {
+		bpath := joinPath(path, b.Name)
+		l := level + 1
+		if l > 6 {
+			l = 6
+		}
+		fmt.Fprintf(sb, "\n%s `%s`\n", strings.Repeat("#", l), bpath)
+		fmt.Fprintf(sb, "\nRequired: %s. Repeat: %s.\n",
+			yesNo(b.Require), yesNo(b.Repeat))
+		if len(b.Labels) > 0 {
+			fmt.Fprintf(sb, "\nLabels: `%s`.\n",
+				strings.Join(b.Labels, "`, `"))
+		}
+		if b.Deprecated != "" {
+			fmt.Fprintf(sb, "\nDeprecated: %s\n", b.Deprecated)
+		}
+		if len(b.Aliases) > 0 {
+			fmt.Fprintf(sb, "\nAliases: `%s`.\n",
+				strings.Join(b.Aliases, "`, `"))
+		}
+		if b.Description != "" {
+			fmt.Fprintf(sb, "\n%s\n", b.Description)
+		}
+		if b.Example != "" {
+			fmt.Fprintf(sb, "\nExample:\n\n```\n%s\n```\n", b.Example)
+		}
+		markdownBlock(sb, bpath, l, b.Properties, b.Blocks)
+	}
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// Manpage generates reference documentation for all properties and blocks
+// described by the specification in man-page (roff) format. Name is the
+// name of the configuration file and section is man-page section number,
+// usually 5.
+func Manpage(spec *Spec, name string, section int) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, ".TH %s %d\n", roffEscape(strings.ToUpper(name)), section)
+	sb.WriteString(".SH NAME\n")
+	fmt.Fprintf(&sb, "%s \\- configuration file\n", roffEscape(name))
+	if len(spec.Properties) > 0 {
+		sb.WriteString(".SH PROPERTIES\n")
+		manProperties(&sb, spec.Properties)
+	}
+	if len(spec.Blocks) > 0 {
+		sb.WriteString(".SH BLOCKS\n")
+		manBlocks(&sb, "", spec.Blocks)
+	}
+
+	return sb.String()
+}
+
+func manProperties(sb *strings.Builder, props []*PropertySpec) {
+	for _, p := range props {
+		sb.WriteString(".TP\n")
+		fmt.Fprintf(sb, ".B %s\n", roffEscape(p.Name))
+		attrs := []string{p.Type.String()}
+		if p.Require {
+			attrs = append(attrs, "required")
+		}
+		if p.Repeat {
+			attrs = append(attrs, "repeat")
+		}
+		if len(p.Enum) > 0 {
+			attrs = append(attrs,
+				"values: "+strings.Join(p.Enum, " | "))
+		}
+		if p.Default != nil {
+			attrs = append(attrs,
+				"default: "+formatValue(p.Type, p.Default))
+		}
+		fmt.Fprintf(sb, "(%s)\n", roffEscape(strings.Join(attrs, ", ")))
+		manDeprecated(sb, p.Aliases, p.Deprecated)
+		if p.Description != "" {
+			sb.WriteString(roffEscape(p.Description) + "\n")
+		}
+		if p.Example != "" {
+			fmt.Fprintf(sb, "Example: %s\n", roffEscape(p.Example))
+		}
+	}
+}
+
+func manBlocks(sb *strings.Builder, path string, blocks []*BlockSpec) {
+	for _, b := range blocks {
+		bpath := joinPath(path, b.Name)
+		fmt.Fprintf(sb, ".SS %s\n", roffEscape(bpath))
+		var attrs []string
+		if b.Require {
+			attrs = append(attrs, "required")
+		}
+		if b.Repeat {
+			attrs = append(attrs, "repeat")
+		}
+		if len(b.Labels) > 0 {
+			attrs = append(attrs,
+				"labels: "+strings.Join(b.Labels, ", "))
+		}
+		if len(attrs) > 0 {
+			fmt.Fprintf(sb, "(%s)\n",
+				roffEscape(strings.Join(attrs, ", ")))
+		}
+		manDeprecated(sb, b.Aliases, b.Deprecated)
+		if b.Description != "" {
+			sb.WriteString(roffEscape(b.Description) + "\n")
+		}
+		if b.Example != "" {
+			sb.WriteString(".PP\nExample:\n.nf\n")
+			sb.WriteString(roffEscape(b.Example) + "\n")
+			sb.WriteString(".fi\n")
+		}
+		manProperties(sb, b.Properties)
+		manBlocks(sb, bpath, b.Blocks)
+	}
+}
+
+func manDeprecated(sb *strings.Builder, aliases []string,
+	deprecated string) {
+
+	if deprecated != "" {
+		fmt.Fprintf(sb, "Deprecated: %s\n", roffEscape(deprecated))
+	}
+	if len(aliases) > 0 {
+		fmt.Fprintf(sb, "Aliases: %s\n",
+			roffEscape(strings.Join(aliases, ", ")))
+	}
+}
+
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\e")
+	s = strings.ReplaceAll(s, "-", "\\-")
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			lines[i] = "\\&" + l
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// formatValue formats property value using configuration file syntax.
+func formatValue(typ Type, v any) string {
+	switch typ {
+	case TypeBool:
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b)
+		}
+	case TypeDuration:
+		if d, ok := v.(time.Duration); ok {
+			return d.String()
+		}
+	case TypeInt:
+		if i, ok := v.(int); ok {
+			return strconv.Itoa(i)
+		}
+	case TypeString:
+		if s, ok := v.(string); ok {
+			return quote(s)
+		}
+	case TypeStringList:
+		if l, ok := v.([]string); ok {
+			var qs []string
+			for _, s := range l {
+				qs = append(qs, quote(s))
+			}
+			return strings.Join(qs, ", ")
+		}
+	}
+
+	return fmt.Sprint(v)
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+
+	return "\"" + strings.ReplaceAll(s, "\"", "\\\"") + "\""
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+var docSpec = &Spec{
+	Properties: []*PropertySpec{
+		&PropertySpec{
+			Type:        TypeInt,
+			Name:        "port",
+			Require:     true,
+			Description: "TCP port to listen on.",
+			Example:     "8080",
+		},
+		&PropertySpec{
+			Type:    TypeDuration,
+			Name:    "timeout",
+			Default: time.Second * 30,
+		},
+	},
+	Blocks: []*BlockSpec{
+		&BlockSpec{
+			Name:        "db",
+			Require:     true,
+			Description: "Database connection.",
+			Properties: []*PropertySpec{
+				&PropertySpec{
+					Type:    TypeString,
+					Name:    "host",
+					Default: "localhost",
+				},
+			},
+			Blocks: []*BlockSpec{
+				&BlockSpec{
+					Name:   "*",
+					Repeat: true,
+					Properties: []*PropertySpec{
+						&PropertySpec{
+							Type:   TypeStringList,
+							Name:   "tags",
+							Repeat: true,
+						},
+					},
+				},
+			},
+		},
+		&BlockSpec{
+			Name:   "upstream",
+			Labels: []string{"name"},
+			Properties: []*PropertySpec{
+				&PropertySpec{
+					Type:    TypeString,
+					Name:    "url",
+					Require: true,
+				},
+			},
+		},
+	},
+}
+
+func TestMarkdown(t *testing.T) {
+	exp := "# example.conf\n" +
+		"\n" +
+		"| Name | Type | Required | Repeat | Default | Description |\n" +
+		"|------|------|----------|--------|---------|-------------|\n" +
+		"| `port` | int | yes | no |  | TCP port to listen on. Example: `8080`. |\n" +
+		"| `timeout` | duration | no | no | `30s` |  |\n" +
+		"\n" +
+		"## `db`\n" +
+		"\n" +
+		"Required: yes. Repeat: no.\n" +
+		"\n" +
+		"Database connection.\n" +
+		"\n" +
+		"| Name | Type | Required | Repeat | Default | Description |\n" +
+		"|------|------|----------|--------|---------|-------------|\n" +
+		"| `host` | string | no | no | `\"localhost\"` |  |\n" +
+		"\n" +
+		"### `db.*`\n" +
+		"\n" +
+		"Required: no. Repeat: yes.\n" +
+		"\n" +
+		"| Name | Type | Required | Repeat | Default | Description |\n" +
+		"|------|------|----------|--------|---------|-------------|\n" +
+		"| `tags` | stringlist | no | yes |  |  |\n" +
+		"\n" +
+		"## `upstream`\n" +
+		"\n" +
+		"Required: no. Repeat: no.\n" +
+		"\n" +
+		"Labels: `name`.\n" +
+		"\n" +
+		"| Name | Type | Required | Repeat | Default | Description |\n" +
+		"|------|------|----------|--------|---------|-------------|\n" +
+		"| `url` | string | yes | no |  |  |\n"
+	assert(t, exp, Markdown(docSpec, "example.conf"))
+}
+
+func TestManpage(t *testing.T) {
+	exp := ".TH EXAMPLE.CONF 5\n" +
+		".SH NAME\n" +
+		"example.conf \\- configuration file\n" +
+		".SH PROPERTIES\n" +
+		".TP\n" +
+		".B port\n" +
+		"(int, required)\n" +
+		"TCP port to listen on.\n" +
+		"Example: 8080\n" +
+		".TP\n" +
+		".B timeout\n" +
+		"(duration, default: 30s)\n" +
+		".SH BLOCKS\n" +
+		".SS db\n" +
+		"(required)\n" +
+		"Database connection.\n" +
+		".TP\n" +
+		".B host\n" +
+		"(string, default: \"localhost\")\n" +
+		".SS db.*\n" +
+		"(repeat)\n" +
+		".TP\n" +
+		".B tags\n" +
+		"(stringlist, repeat)\n" +
+		".SS upstream\n" +
+		"(labels: name)\n" +
+		".TP\n" +
+		".B url\n" +
+		"(string, required)\n"
+	assert(t, exp, Manpage(docSpec, "example.conf", 5))
+}
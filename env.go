@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// SourceEnv is the Property.Source of properties set with environment
+// variables.
+const SourceEnv = "<env>"
+
+// Env maps environment variables to configuration properties, so file
+// values can be overridden with the environment. Variable is named
+// after the property path, e.g. with "APP_" prefix server.port property
+// is set with APP_SERVER_PORT variable. Properties and values are handled
+// the same way as flags are, see overridable.
+type Env struct {
+	// Prefix of all variable names, like "APP_".
+	Prefix string
+	// Transform converts property path into variable name without
+	// prefix. EnvName is used if nil.
+	Transform func(path []string) string
+	// Lookup returns value of the variable and reports whether it is
+	// set. os.LookupEnv is used if nil.
+	Lookup func(name string) (string, bool)
+}
+
+// EnvName converts property path into environment variable name: path
+// elements are joined with underscores, converted to upper case and
+// all characters except letters and digits are replaced with
+// underscores, e.g. server.max-conn becomes SERVER_MAX_CONN.
+func EnvName(path []string) string {
+	s := strings.ToUpper(strings.Join(path, "_"))
+
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// Config returns configuration which consists of properties set with
+// environment variables.
+func (e *Env) Config(spec *Spec) (*Config, error) {
+	transform := e.Transform
+	if transform == nil {
+		transform = EnvName
+	}
+	lookup := e.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	root := &Block{Name: rootBlock}
+	var err error
+	overridable(nil, spec.Properties, spec.Blocks,
+		func(names []string, p *PropertySpec) {
+			if err != nil {
+				return
+			}
+			name := e.Prefix + transform(names)
+			s, ok := lookup(name)
+			if !ok {
+				return
+			}
+			v, cerr := convertText(p, s)
+			if cerr != nil {
+				err = fmt.Errorf("%s: %s", name, cerr)
+				return
+			}
+			setProperty(root, names, p, v, SourceEnv)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{root.Properties, root.Blocks}, nil
+}
+
+// Apply overlays properties set with environment variables onto the
+// configuration. See Merge.
+func (e *Env) Apply(spec *Spec, cfg *Config) (*Config, error) {
+	env, err := e.Config(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return Merge(spec, cfg, env)
+}
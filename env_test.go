@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	assert(t, "SERVER_PORT", EnvName([]string{"server", "port"}))
+	assert(t, "DB_MAX_CONN", EnvName([]string{"db", "max-conn"}))
+}
+
+func TestEnv(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeStringList, Name: "hosts"},
+			&PropertySpec{Type: TypeString, Name: "level",
+				Enum: []string{"info", "error"}},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "server",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "port",
+						Require: true},
+					&PropertySpec{Type: TypeBool, Name: "tls"},
+				},
+			},
+		},
+	}
+	cfg, err := Parse(spec, `
+                level = "info"
+                server {
+                    port = 80
+                    tls = false
+                }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{
+		"APP_HOSTS":       "a, b",
+		"APP_SERVER_PORT": "9090",
+		"SERVER_TLS":      "true",
+	}
+	env := &Env{
+		Prefix: "APP_",
+		Lookup: func(name string) (string, bool) {
+			v, ok := vars[name]
+			return v, ok
+		},
+	}
+	cfg, err = env.Apply(spec, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, []string{"a", "b"}, cfg.StringList("hosts"))
+	assert(t, "info", cfg.String("level"))
+	assert(t, 9090, cfg.Block("server").Int("port"))
+	assert(t, SourceEnv, cfg.Block("server").Property("port").Source)
+	assert(t, false, cfg.Block("server").Bool("tls"))
+
+	env.Transform = func(path []string) string {
+		return strings.ToLower(strings.Join(path, "."))
+	}
+	vars = map[string]string{"APP_server.port": "x"}
+	_, err = env.Apply(spec, cfg)
+	if err == nil ||
+		err.Error() != "APP_server.port: invalid integer value" {
+
+		t.Fatal(err)
+	}
+	// Values are validated like configuration file values are.
+	vars = map[string]string{"APP_server.port": "-1"}
+	_, err = env.Apply(spec, cfg)
+	if err == nil || err.Error() != "APP_server.port: unexpected `-`" {
+		t.Fatal(err)
+	}
+	vars = map[string]string{"APP_level": "eror"}
+	_, err = env.Apply(spec, cfg)
+	exp := "APP_level: invalid value `eror`, expected one of: " +
+		"info, error (did you mean `error`?)"
+	if err == nil || err.Error() != exp {
+		t.Fatal(err)
+	}
+}
@@ -1,16 +1,160 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-type parseError struct {
-	line int
-	msg  string
+// ErrorCode is a machine-readable kind of the parse error.
+type ErrorCode string
+
+const (
+	// Malformed input: unexpected character or token, unterminated
+	// string or block, etc.
+	CodeSyntax ErrorCode = "syntax"
+	// Property value does not match property type.
+	CodeType ErrorCode = "type"
+	// Value is rejected by the property custom Parser.
+	CodeInvalidValue ErrorCode = "invalid-value"
+	// Property is not described by the specification.
+	CodeUnsupportedProperty ErrorCode = "unsupported-property"
+	// Block is not described by the specification.
+	CodeUnsupportedBlock ErrorCode = "unsupported-block"
+	// Non-repeatable property is defined more than once.
+	CodeDuplicateProperty ErrorCode = "duplicate-property"
+	// Non-repeatable block is defined more than once.
+	CodeDuplicateBlock ErrorCode = "duplicate-block"
+	// Required property is not defined.
+	CodeMissingProperty ErrorCode = "missing-property"
+	// Required block is not defined.
+	CodeMissingBlock ErrorCode = "missing-block"
+	// Deprecated property or block name is used.
+	CodeDeprecated ErrorCode = "deprecated"
+	// Number of block labels does not match the specification.
+	CodeLabels ErrorCode = "labels"
+	// Block template is unknown, defined more than once or never used.
+	CodeTemplate ErrorCode = "template"
+)
+
+// ParseError describes problem found in the configuration file.
+type ParseError struct {
+	// File name. Empty if configuration is not read from a file.
+	File string
+	// Line number, starting at 1.
+	Line int
+	// Column number (byte count in the line), starting at 1.
+	Column int
+	// Byte offset from the beginning of the input, starting at 0.
+	Offset int
+	Code   ErrorCode
+	Msg    string
+	// Underlying error if any, like the one returned by custom Parser.
+	Err error
+	// Similar names or values which can be used instead of misspelled
+	// ones.
+	Suggestions []string
+	// Source line the error points to.
+	src string
+}
+
+func newError(t *Tokenizer, pos Position, code ErrorCode, format string,
+	args ...any) *ParseError {
+
+	return &ParseError{
+		Line:   pos.Line,
+		Column: pos.Column,
+		Offset: pos.Offset,
+		Code:   code,
+		Msg:    fmt.Sprintf(format, args...),
+		src:    t.sourceLine(pos.Offset),
+	}
+}
+
+func wrapError(t *Tokenizer, pos Position, code ErrorCode,
+	err error) *ParseError {
+
+	e := newError(t, pos, code, "%s", err.Error())
+	e.Err = err
+
+	return e
+}
+
+func (e *ParseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+	}
+
+	return fmt.Sprintf("%d: %s", e.Line, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
 
-func newError(line int, format string, args ...any) *parseError {
-	return &parseError{line, fmt.Sprintf(format, args...)}
+// Snippet renders the offending source line with a caret under the
+// error column.
+func (e *ParseError) Snippet() string {
+	var sb strings.Builder
+
+	sb.WriteString(e.src)
+	sb.WriteString("\n")
+	for i, r := range e.src {
+		if i >= e.Column-1 {
+			break
+		}
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	sb.WriteString("^")
+
+	return sb.String()
 }
 
-func (e parseError) Error() string {
-	return fmt.Sprintf("%d: %s", e.line, e.msg)
+// Warning describes non-fatal problem found in the configuration file.
+type Warning struct {
+	// File name. Empty if configuration is not read from a file.
+	File string
+	// Line number, starting at 1.
+	Line int
+	// Column number (byte count in the line), starting at 1.
+	Column int
+	// Byte offset from the beginning of the input, starting at 0.
+	Offset int
+	Code   ErrorCode
+	Msg    string
+	// Similar names which can be used instead of misspelled ones.
+	Suggestions []string
+}
+
+func (w *Warning) String() string {
+	if w.File != "" {
+		return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Msg)
+	}
+
+	return fmt.Sprintf("%d: %s", w.Line, w.Msg)
+}
+
+// ErrorList is a list of parse errors returned in error-recovering parse
+// mode. It can be inspected with errors.Is and errors.As functions.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+func (l ErrorList) Unwrap() []error {
+	var errs []error
+	for _, e := range l {
+		errs = append(errs, e)
+	}
+
+	return errs
 }
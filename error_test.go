@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "foo"},
+		},
+		Strict: true,
+	}
+	_, err := Parse(spec, "foo = 1\nfoo = 2\n")
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	assert(t, CodeDuplicateProperty, perr.Code)
+	assert(t, 2, perr.Line)
+	assert(t, 1, perr.Column)
+	assert(t, 8, perr.Offset)
+	assert(t, "foo = 2\n^", perr.Snippet())
+
+	_, err = Parse(spec, "\tfoo = \"bar\"")
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	assert(t, CodeType, perr.Code)
+	assert(t, 8, perr.Column)
+	assert(t, "\tfoo = \"bar\"\n\t      ^", perr.Snippet())
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	spec := &Spec{
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "foo",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "bar",
+						Require: true},
+				},
+			},
+		},
+	}
+	_, err := Parse(spec, "\n  foo {\n\n}\n")
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	assert(t, CodeMissingProperty, perr.Code)
+	assert(t, 2, perr.Line)
+	assert(t, 3, perr.Column)
+}
+
+func TestParseErrorWrap(t *testing.T) {
+	errInvalid := errors.New("invalid")
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "foo",
+				Parser: func(v any) (any, error) {
+					return nil, errInvalid
+				}},
+		},
+	}
+	_, err := Parse(spec, "foo = 1")
+	if !errors.Is(err, errInvalid) {
+		t.Fatal(err)
+	}
+}
+
+func TestParseErrorFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.conf")
+	err := os.WriteFile(file, []byte("foo = 1"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ParseFile(&Spec{Strict: true}, file)
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	assert(t, file, perr.File)
+	assert(t, CodeUnsupportedProperty, perr.Code)
+	assert(t, file+":1: unsupported property: foo", err.Error())
+
+	_, err = Parse(&Spec{Strict: true}, "foo = 1", Source("cli"))
+	assert(t, "cli:1: unsupported property: foo", err.Error())
+}
+
+func TestParseCollectErrors(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "foo"},
+			&PropertySpec{Type: TypeInt, Name: "req", Require: true},
+			&PropertySpec{Type: TypeStringList, Name: "lst"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "blk",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "num"},
+				},
+				Strict: true,
+			},
+		},
+		Strict: true,
+	}
+	s := "foo = x\n" +
+		"bar = 1, 2\n" +
+		"baz { a = 1; b { c = 2 } }\n" +
+		"foo = 2\n" +
+		"foo = 3\n" +
+		"qux\n" +
+		"lst = \"a\", 1, \"b\"\n" +
+		"blk {\n" +
+		"  num = \"s\"\n" +
+		"}\n"
+	cfg, err := Parse(spec, s, CollectErrors())
+	if cfg != nil {
+		t.Fatal(cfg)
+	}
+	exp := "1: invalid integer value\n" +
+		"2: unsupported property: bar\n" +
+		"3: unsupported block: baz\n" +
+		"5: property `foo` already defined\n" +
+		"6: `=` or `{` expected\n" +
+		"7: string value expected\n" +
+		"9: integer value expected\n" +
+		"1: missing required property `req`"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+	var errs ErrorList
+	if !errors.As(err, &errs) || len(errs) != 8 {
+		t.Fatal(err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Code != CodeType {
+		t.Fatal(err)
+	}
+
+	_, err = Parse(spec, "req = 1", CollectErrors())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Property with invalid value is not reported as missing.
+	_, err = Parse(spec, "req = x", CollectErrors())
+	assert(t, "1: invalid integer value", err.Error())
+
+	// Parsing is resumed after `;` on the same line.
+	s = "foo 1; req = x\n" +
+		"lst = \"a\", 1; foo = y; req = 1\n"
+	_, err = Parse(spec, s, CollectErrors())
+	exp = "1: `=` expected\n" +
+		"1: invalid integer value\n" +
+		"2: string value expected\n" +
+		"2: invalid integer value"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+}
+
+func TestParseWarnings(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "workers"},
+		},
+		Strict: false,
+	}
+	var ws []string
+	onWarning := func(w *Warning) {
+		ws = append(ws, w.String())
+		assert(t, CodeUnsupportedProperty, w.Code)
+	}
+	_, err := Parse(spec, "workers = 1\nwokers = 2\nfoo = \"a\", \"b\"",
+		OnWarning(onWarning))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, []string{
+		"2: unsupported property ignored: wokers (did you mean `workers`?)",
+		"3: unsupported property ignored: foo",
+	}, ws)
+}
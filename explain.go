@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explain returns the configuration in configuration file syntax where
+// every property and block is annotated with a comment which tells
+// where it comes from, like file name with line and column numbers or
+// SourceEnv. It is useful to debug layered configurations built with
+// Merge.
+func Explain(cfg *Config) string {
+	var sb strings.Builder
+
+	explainBlock(&sb, 0, cfg.Properties, cfg.Blocks)
+
+	return sb.String()
+}
+
+func explainBlock(sb *strings.Builder, depth int, props []*Property,
+	blocks []*Block) {
+
+	indent := strings.Repeat(sampleIndent, depth)
+	for _, p := range props {
+		fmt.Fprintf(sb, "%s%s = %s%s\n", indent, p.Name,
+			formatValue(p.Type, p.Value), origin(p.Source, p.Pos))
+	}
+	for _, b := range blocks {
+		fmt.Fprintf(sb, "%s%s {%s\n", indent,
+			blockTitle(b.Name, b.Labels), origin(b.Source, b.Pos))
+		explainBlock(sb, depth+1, b.Properties, b.Blocks)
+		fmt.Fprintf(sb, "%s}\n", indent)
+	}
+}
+
+// origin formats source and position as a configuration file comment.
+func origin(source string, pos Position) string {
+	s := source
+	if pos.Line > 0 {
+		if s != "" {
+			s += ":"
+		}
+		s += pos.String()
+	}
+	if s == "" {
+		return ""
+	}
+
+	return " # " + s
+}
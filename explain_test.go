@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeDuration, Name: "timeout",
+				Default: time.Second},
+			&PropertySpec{Type: TypeStringList, Name: "hosts"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name:   "upstream",
+				Labels: []string{"name"},
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "port"},
+				},
+			},
+			&BlockSpec{
+				Name: "server",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "port",
+						Default: 80},
+				},
+			},
+		},
+	}
+	file, err := Parse(spec, "hosts = \"a\", \"b\"\n"+
+		"upstream \"api\" {\n"+
+		"    port = 8080\n"+
+		"}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := (&Env{Lookup: func(name string) (string, bool) {
+		return "90", name == "SERVER_PORT"
+	}}).Config(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Merge(spec, Defaults(spec), file, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "timeout = 1s # <default>\n" +
+		"hosts = \"a\", \"b\" # 1:1\n" +
+		"server { # <default>\n" +
+		"    port = 90 # <env>\n" +
+		"}\n" +
+		"upstream \"api\" { # 2:1\n" +
+		"    port = 8080 # 3:5\n" +
+		"}\n"
+	assert(t, exp, Explain(cfg))
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// SourceFlag is the Property.Source of properties set with command line
+// flags.
+const SourceFlag = "<flag>"
+
+// Flags binds configuration properties to command line flags, so file
+// values can be overridden from the command line.
+type Flags struct {
+	spec *Spec
+	root *Block
+}
+
+// NewFlags registers flag for every property described by the
+// specification in the flag set, see overridable for which properties
+// get flags and how values are converted. Flag is named after the
+// property path, like -server.port. Properties which names clash with
+// already defined flags are skipped. Repeated property flag can be given
+// more than once.
+//
+// Additionally -set flag is registered which accepts any property in
+// path=value form, like -set server.port=9090. Non-repeated blocks with
+// star-names can be set this way too, like -set disk1.size=100.
+func NewFlags(spec *Spec, fs *flag.FlagSet) *Flags {
+	f := &Flags{spec: spec, root: &Block{Name: rootBlock}}
+	overridable(nil, spec.Properties, spec.Blocks,
+		func(names []string, p *PropertySpec) {
+			name := strings.Join(names, ".")
+			if fs.Lookup(name) != nil {
+				return
+			}
+			v := &flagValue{f: f, names: names, spec: p}
+			if p.Default != nil {
+				v.text = formatValue(p.Type, p.Default)
+			}
+			fs.Var(v, name, p.Description)
+		})
+	if fs.Lookup("set") == nil {
+		fs.Var(&setValue{f}, "set",
+			"set configuration property, `path=value`")
+	}
+
+	return f
+}
+
+// overridable calls fn for every property which can be overridden with
+// flags or environment variables or which default value is used by
+// Defaults. Properties with star-names, deprecated properties and
+// properties of repeated, labeled or star-blocks are skipped, since they
+// can not be addressed with a plain path.
+//
+// Flag and environment values are converted with convertText, by the same
+// rules as configuration file values are, except strings which are not
+// quoted and string list items which are separated with commas.
+func overridable(path []string, props []*PropertySpec, blocks []*BlockSpec,
+	fn func(names []string, spec *PropertySpec)) {
+
+	for _, p := range props {
+		if strings.Contains(p.Name, "*") || p.Deprecated != "" {
+			continue
+		}
+		fn(append(append([]string(nil), path...), p.Name), p)
+	}
+	for _, b := range blocks {
+		if strings.Contains(b.Name, "*") || b.Repeat ||
+			len(b.Labels) > 0 || b.Deprecated != "" {
+
+			continue
+		}
+		bpath := append(append([]string(nil), path...), b.Name)
+		overridable(bpath, b.Properties, b.Blocks, fn)
+	}
+}
+
+// Config returns configuration which consists of properties set with
+// command line flags.
+func (f *Flags) Config() *Config {
+	c := copyBlock(f.root)
+
+	return &Config{c.Properties, c.Blocks}
+}
+
+// Apply overlays properties set with command line flags onto the
+// configuration. See Merge.
+func (f *Flags) Apply(cfg *Config) (*Config, error) {
+	return Merge(f.spec, cfg, f.Config())
+}
+
+// set converts the text value and stores property with the given path.
+func (f *Flags) set(names []string, spec *PropertySpec, text string) error {
+	v, err := convertText(spec, text)
+	if err != nil {
+		return err
+	}
+	setProperty(f.root, names, spec, v, SourceFlag)
+
+	return nil
+}
+
+// setProperty stores property with the given path in the block creating
+// nested blocks if needed. Value of non-repeated property is replaced.
+func setProperty(b *Block, names []string, spec *PropertySpec, v any,
+	source string) {
+
+	for _, n := range names[:len(names)-1] {
+		c := b.Block(n)
+		if c == nil {
+			c = &Block{Name: n, Source: source}
+			b.Blocks = append(b.Blocks, c)
+		}
+		b = c
+	}
+	name := names[len(names)-1]
+	if !spec.Repeat {
+		var ps []*Property
+		for _, p := range b.Properties {
+			if p.Name != name {
+				ps = append(ps, p)
+			}
+		}
+		b.Properties = ps
+	}
+	b.Properties = append(b.Properties, &Property{
+		Type:   spec.Type,
+		Name:   name,
+		Value:  v,
+		Source: source,
+	})
+}
+
+// flagValue is a flag.Value of the single property flag.
+type flagValue struct {
+	f     *Flags
+	names []string
+	spec  *PropertySpec
+	text  string
+}
+
+func (v *flagValue) String() string {
+	return v.text
+}
+
+func (v *flagValue) Set(s string) error {
+	if err := v.f.set(v.names, v.spec, s); err != nil {
+		return err
+	}
+	v.text = s
+
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.spec != nil && v.spec.Type == TypeBool
+}
+
+// setValue is a flag.Value of the -set flag.
+type setValue struct {
+	f *Flags
+}
+
+func (v *setValue) String() string {
+	return ""
+}
+
+func (v *setValue) Set(s string) error {
+	path, text, ok := strings.Cut(s, "=")
+	if !ok {
+		return errors.New("path=value expected")
+	}
+	root := &BlockSpec{
+		Properties: v.f.spec.Properties,
+		Blocks:     v.f.spec.Blocks,
+	}
+	names, spec := findPath(root, strings.TrimSpace(path))
+	if spec == nil {
+		return fmt.Errorf("unsupported property: %s", path)
+	}
+
+	return v.f.set(names, spec, text)
+}
+
+// findPath returns canonical names of the blocks and property addressed
+// by the dot-separated path and the property specification. Properties
+// of repeated and labeled blocks can not be addressed, since it is not
+// clear which of the blocks to override.
+func findPath(spec *BlockSpec, path string) ([]string, *PropertySpec) {
+	for _, s := range spec.Properties {
+		if s.Name == path {
+			return []string{path}, s
+		}
+	}
+	for i, c := range path {
+		if c != '.' {
+			continue
+		}
+		s := findBlock(spec.Blocks, path[:i])
+		if s == nil || s.Repeat || len(s.Labels) > 0 {
+			continue
+		}
+		names, ps := findPath(s, path[i+1:])
+		if ps != nil {
+			return append([]string{specName(path[:i], s.Name)},
+				names...), ps
+		}
+	}
+	if s := findProperty(spec.Properties, path); s != nil {
+		return []string{specName(path, s.Name)}, s
+	}
+
+	return nil, nil
+}
+
+// specName returns canonical name for the name matched by the spec name
+// pattern directly or with an alias.
+func specName(name string, pattern string) string {
+	if MatchName(name, pattern) {
+		return name
+	}
+
+	return pattern
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestFlags(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeBool, Name: "debug"},
+			&PropertySpec{Type: TypeString, Name: "level",
+				Enum: []string{"info", "error"}},
+			&PropertySpec{Type: TypeString, Name: "tag", Repeat: true},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "server",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "port",
+						Require: true},
+					&PropertySpec{Type: TypeDuration,
+						Name: "timeout", Default: time.Second},
+				},
+			},
+			&BlockSpec{
+				Name: "disk*",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "size"},
+				},
+			},
+			&BlockSpec{
+				Name:   "part*",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "size"},
+				},
+			},
+		},
+	}
+	cfg, err := Parse(spec, `
+                level = "error"
+                tag = "a"
+                server {
+                    port = 80
+                    timeout = 5s
+                }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	f := NewFlags(spec, fs)
+	assert(t, "1s", fs.Lookup("server.timeout").DefValue)
+	if fs.Lookup("disk*.size") != nil {
+		t.Fatal("star-block flag registered")
+	}
+	err = fs.Parse([]string{"-debug", "-server.port", "9090",
+		"-tag", "b", "-tag", "c", "-set", "disk1.size=10",
+		"-set", "server.port=8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = f.Apply(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, true, cfg.Bool("debug"))
+	assert(t, "error", cfg.String("level"))
+	assert(t, []string{"b", "c"}, cfg.Strings("tag"))
+	assert(t, 8080, cfg.Block("server").Int("port"))
+	assert(t, SourceFlag, cfg.Block("server").Property("port").Source)
+	assert(t, 5*time.Second, cfg.Block("server").Duration("timeout"))
+	assert(t, 10, cfg.Block("disk1").Int("size"))
+
+	for _, c := range []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"-server.port", "x"},
+			"invalid value \"x\" for flag -server.port: " +
+				"invalid integer value"},
+		{[]string{"-level", "inf"},
+			"invalid value \"inf\" for flag -level: invalid value " +
+				"`inf`, expected one of: info, error " +
+				"(did you mean `info`?)"},
+		{[]string{"-set", "server.host=x"},
+			"invalid value \"server.host=x\" for flag -set: " +
+				"unsupported property: server.host"},
+		{[]string{"-server.port", "-1"},
+			"invalid value \"-1\" for flag -server.port: " +
+				"unexpected `-`"},
+		{[]string{"-server.port", "+5"},
+			"invalid value \"+5\" for flag -server.port: " +
+				"unexpected `+`"},
+		{[]string{"-server.port", "1 2"},
+			"invalid value \"1 2\" for flag -server.port: " +
+				"single value expected"},
+		{[]string{"-set", "part1.size=1"},
+			"invalid value \"part1.size=1\" for flag -set: " +
+				"unsupported property: part1.size"},
+		{[]string{"-set", "debug"},
+			"invalid value \"debug\" for flag -set: " +
+				"path=value expected"},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		NewFlags(spec, fs)
+		err := fs.Parse(c.args)
+		if err == nil || err.Error() != c.err {
+			t.Fatal(c.args, err)
+		}
+	}
+}
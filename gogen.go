@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Common initialisms which are written in upper case in Go names.
+var goInitialisms = map[string]bool{
+	"acl": true, "api": true, "cpu": true, "db": true, "dns": true,
+	"eof": true, "gid": true, "html": true, "http": true, "https": true,
+	"id": true, "io": true, "ip": true, "json": true, "rpc": true,
+	"sql": true, "ssh": true, "tcp": true, "tls": true, "ttl": true,
+	"udp": true, "uid": true, "uri": true, "url": true, "uuid": true,
+	"xml": true,
+}
+
+type goType struct {
+	getter string
+	typ    string
+}
+
+var goTypes = map[Type]goType{
+	TypeBool:       goType{"Bool", "bool"},
+	TypeDuration:   goType{"Duration", "time.Duration"},
+	TypeInt:        goType{"Int", "int"},
+	TypeString:     goType{"String", "string"},
+	TypeStringList: goType{"StringList", "[]string"},
+}
+
+type goGen struct {
+	buf   bytes.Buffer
+	types map[string]bool
+	time  bool
+}
+
+// GenerateGo generates Go source file with typed accessors for the
+// configuration described by the specification. Generated file belongs
+// to pkg package and defines typ type which wraps *config.Config. Every
+// block gets its own wrapper type with accessor methods named after
+// properties and nested blocks, so misspelled names fail at compile time.
+//
+// Repeated properties and blocks are returned as slices, star-blocks are
+// returned as maps keyed by block name and labeled blocks are returned as
+// nested maps, one level per label. Properties with star-names are not
+// supported and skipped. Optional properties return default value from
+// the spec (or zero value) when not set and get Has method.
+func GenerateGo(spec *Spec, pkg string, typ string) ([]byte, error) {
+	g := &goGen{types: map[string]bool{}}
+
+	err := g.genType(typ, "*config.Config", "", spec.Properties, spec.Blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by config gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString("import (\n")
+	if g.time {
+		out.WriteString("\t\"time\"\n\n")
+	}
+	out.WriteString("\t\"github.com/vchimishuk/config\"\n)\n")
+	out.Write(g.buf.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+func (g *goGen) genType(name string, node string, parent string,
+	props []*PropertySpec, blocks []*BlockSpec) error {
+
+	if g.types[name] {
+		return fmt.Errorf("duplicate type name: %s", name)
+	}
+	g.types[name] = true
+	methods := map[string]bool{}
+	method := func(m string) error {
+		if methods[m] {
+			return fmt.Errorf("duplicate method name: %s.%s", name, m)
+		}
+		methods[m] = true
+		return nil
+	}
+
+	b := &g.buf
+	if parent == "" {
+		fmt.Fprintf(b, "\n// %s provides typed access to the configuration.\n",
+			name)
+	} else {
+		fmt.Fprintf(b, "\n// %s provides typed access to the block.\n", name)
+	}
+	fmt.Fprintf(b, "type %s struct {\n\tn %s\n}\n", name, node)
+	if parent == "" {
+		fmt.Fprintf(b, "\nfunc New%s(c *config.Config) *%s {\n"+
+			"\treturn &%s{c}\n}\n", name, name, name)
+	}
+
+	for _, p := range props {
+		if strings.Contains(p.Name, "*") {
+			continue
+		}
+		m := goName(p.Name)
+		if err := method(m); err != nil {
+			return err
+		}
+		t, ok := goTypes[p.Type]
+		if !ok {
+			panic("unsupported Type")
+		}
+		if p.Parser != nil {
+			// Custom parser can return value of any type.
+			t = goType{"Any", "any"}
+		}
+		if p.Type == TypeDuration && p.Parser == nil {
+			g.time = true
+		}
+		q := strconv.Quote(p.Name)
+
+		goComment(b, p.Description)
+		if p.Repeat {
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() []%s {\n"+
+				"\treturn x.n.%ss(%s)\n}\n",
+				name, m, t.typ, t.getter, q)
+		} else if p.Require {
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() %s {\n"+
+				"\treturn x.n.%s(%s)\n}\n",
+				name, m, t.typ, t.getter, q)
+		} else {
+			def := "nil"
+			if t.getter != "Any" {
+				def = goLiteral(p.Type, p.Default)
+			}
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() %s {\n"+
+				"\treturn x.n.%sOr(%s, %s)\n}\n",
+				name, m, t.typ, t.getter, q, def)
+		}
+		if !p.Require {
+			if err := method("Has" + m); err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "\nfunc (x *%s) Has%s() bool {\n"+
+				"\treturn x.n.Has(%s)\n}\n", name, m, q)
+		}
+	}
+
+	for _, s := range blocks {
+		star := strings.Contains(s.Name, "*")
+		bname := goName(strings.ReplaceAll(s.Name, "*", ""))
+		typ := parent + bname
+		if bname == "" {
+			bname = "Item"
+			typ = name + bname
+		}
+		labeled := len(s.Labels) > 0
+		m := bname
+		if star || labeled {
+			m = bname + "Map"
+		}
+		if err := method(m); err != nil {
+			return err
+		}
+
+		goComment(b, s.Description)
+		if labeled {
+			g.labelMap(name, m, typ, s)
+		} else if star {
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() map[string]*%s {\n"+
+				"\tm := map[string]*%s{}\n"+
+				"\tfor _, b := range x.n.Blocks {\n"+
+				"\t\tif _, ok := m[b.Name]; !ok%s {\n"+
+				"\t\t\tm[b.Name] = &%s{b}\n"+
+				"\t\t}\n"+
+				"\t}\n"+
+				"\treturn m\n}\n",
+				name, m, typ, typ, goMatch(s.Name, blocks), typ)
+		} else if s.Repeat {
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() []*%s {\n"+
+				"\tvar bs []*%s\n"+
+				"\tfor _, b := range x.n.Blocks {\n"+
+				"\t\tif b.Name == %s {\n"+
+				"\t\t\tbs = append(bs, &%s{b})\n"+
+				"\t\t}\n"+
+				"\t}\n"+
+				"\treturn bs\n}\n",
+				name, m, typ, typ, strconv.Quote(s.Name), typ)
+		} else {
+			fmt.Fprintf(b, "\nfunc (x *%s) %s() *%s {\n"+
+				"\tb := x.n.Block(%s)\n"+
+				"\tif b == nil {\n"+
+				"\t\treturn nil\n"+
+				"\t}\n"+
+				"\treturn &%s{b}\n}\n",
+				name, m, typ, strconv.Quote(s.Name), typ)
+		}
+
+		err := g.genType(typ, "*config.Block", typ, s.Properties, s.Blocks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// labelMap generates method m of the type name which returns labeled
+// blocks of the spec s as nested maps, one level per label. The first
+// block with the given labels wins.
+func (g *goGen) labelMap(name string, m string, typ string, s *BlockSpec) {
+	b := &g.buf
+	n := len(s.Labels)
+	mtype := func(i int) string {
+		return strings.Repeat("map[string]", n-i) + "*" + typ
+	}
+
+	fmt.Fprintf(b, "\nfunc (x *%s) %s() %s {\n"+
+		"\tm0 := %s{}\n"+
+		"\tfor _, b := range x.n.AllBlocks(%s) {\n"+
+		"\t\tif len(b.Labels) != %d {\n"+
+		"\t\t\tcontinue\n"+
+		"\t\t}\n",
+		name, m, mtype(0), mtype(0), strconv.Quote(s.Name), n)
+	for i := 1; i < n; i++ {
+		fmt.Fprintf(b, "\t\tm%d, ok := m%d[b.Labels[%d]]\n"+
+			"\t\tif !ok {\n"+
+			"\t\t\tm%d = %s{}\n"+
+			"\t\t\tm%d[b.Labels[%d]] = m%d\n"+
+			"\t\t}\n",
+			i, i-1, i-1, i, mtype(i), i-1, i-1, i)
+	}
+	fmt.Fprintf(b, "\t\tif _, ok := m%d[b.Labels[%d]]; !ok {\n"+
+		"\t\t\tm%d[b.Labels[%d]] = &%s{b}\n"+
+		"\t\t}\n"+
+		"\t}\n"+
+		"\treturn m0\n}\n",
+		n-1, n-1, n-1, n-1, typ)
+}
+
+// goMatch returns Go condition which checks that block b belongs to the
+// star-block spec with the given name and not to other, more specific,
+// block spec on the same level. Condition is prefixed with && operator.
+func goMatch(name string, blocks []*BlockSpec) string {
+	var others []string
+	var stars []string
+	for _, s := range blocks {
+		if !strings.Contains(s.Name, "*") {
+			others = append(others, strconv.Quote(s.Name))
+		} else if s.Name > name {
+			// Greater pattern is chosen by the parser, see findBlock.
+			stars = append(stars, strconv.Quote(s.Name))
+		}
+	}
+	sort.Strings(others)
+	sort.Strings(stars)
+
+	var expr string
+	if name != "*" {
+		expr += fmt.Sprintf(" && config.MatchName(b.Name, %s)",
+			strconv.Quote(name))
+	}
+	for _, o := range others {
+		expr += " && b.Name != " + o
+	}
+	for _, p := range stars {
+		expr += " && !config.MatchName(b.Name, " + p + ")"
+	}
+
+	return expr
+}
+
+func goComment(b *bytes.Buffer, s string) {
+	if s == "" {
+		return
+	}
+	b.WriteString("\n// " + strings.ReplaceAll(s, "\n", "\n// "))
+}
+
+// goName converts configuration name into exported Go identifier.
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, w := range words {
+		if goInitialisms[strings.ToLower(w)] {
+			sb.WriteString(strings.ToUpper(w))
+		} else {
+			sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	s := sb.String()
+	if s != "" && unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+
+	return s
+}
+
+// goLiteral returns Go literal for the property value of the given type.
+func goLiteral(typ Type, v any) string {
+	switch typ {
+	case TypeBool:
+		b, _ := v.(bool)
+		return strconv.FormatBool(b)
+	case TypeDuration:
+		d, _ := v.(time.Duration)
+		return fmt.Sprintf("time.Duration(%d)", int64(d))
+	case TypeInt:
+		i, _ := v.(int)
+		return strconv.Itoa(i)
+	case TypeString:
+		s, _ := v.(string)
+		return strconv.Quote(s)
+	case TypeStringList:
+		l, ok := v.([]string)
+		if !ok {
+			return "nil"
+		}
+		var qs []string
+		for _, s := range l {
+			qs = append(qs, strconv.Quote(s))
+		}
+		return "[]string{" + strings.Join(qs, ", ") + "}"
+	default:
+		panic("unsupported Type")
+	}
+}
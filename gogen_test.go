@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoName(t *testing.T) {
+	assert(t, "DB", goName("db"))
+	assert(t, "MaxConnections", goName("max-connections"))
+	assert(t, "TLSCertFile", goName("tls.cert_file"))
+	assert(t, "X2fa", goName("2fa"))
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := GenerateGo(docSpec, "main", "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"func (x *Config) Port() int {",
+		"func (x *Config) Timeout() time.Duration {",
+		"func (x *Config) HasTimeout() bool {",
+		"func (x *Config) DB() *DB {",
+		"func (x *DB) Host() string {",
+		"func (x *DB) ItemMap() map[string]*DBItem {",
+		"func (x *DBItem) Tags() [][]string {",
+		"func (x *Config) UpstreamMap() map[string]*Upstream {",
+		"func (x *Upstream) URL() string {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("%s not found in:\n%s", s, src)
+		}
+	}
+
+	// Labels may contain spaces, so every label gets its own map level.
+	src, err = GenerateGo(&Spec{
+		Blocks: []*BlockSpec{
+			&BlockSpec{Name: "route", Labels: []string{"a", "b"}},
+		},
+	}, "main", "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"func (x *Config) RouteMap() map[string]map[string]*Route {",
+		"m1[b.Labels[1]] = &Route{b}",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("%s not found in:\n%s", s, src)
+		}
+	}
+
+	// Blocks claimed by more specific star-block are excluded.
+	src, err = GenerateGo(&Spec{
+		Blocks: []*BlockSpec{
+			&BlockSpec{Name: "*"},
+			&BlockSpec{Name: "sd*"},
+			&BlockSpec{Name: "boot"},
+		},
+	}, "main", "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"!ok && b.Name != \"boot\" && " +
+			"!config.MatchName(b.Name, \"sd*\") {",
+		"!ok && config.MatchName(b.Name, \"sd*\") && " +
+			"b.Name != \"boot\" {",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("%s not found in:\n%s", s, src)
+		}
+	}
+
+	_, err = GenerateGo(&Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "db"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{Name: "db"},
+		},
+	}, "main", "Config")
+	if err == nil || err.Error() != "duplicate method name: Config.DB" {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateGoBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping build in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	src, err := GenerateGo(docSpec, "main", "Config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	mod := "module example\n\ngo 1.20\n\n" +
+		"require github.com/vchimishuk/config v0.0.0\n\n" +
+		"replace github.com/vchimishuk/config => " + root + "\n"
+	main := `package main
+
+import "github.com/vchimishuk/config"
+
+func main() {
+	c, err := config.Parse(&config.Spec{}, "")
+	if err == nil {
+		_ = NewConfig(c).DB().ItemMap()
+		_ = NewConfig(c).UpstreamMap()
+	}
+}
+`
+	files := map[string]string{
+		"go.mod":        mod,
+		"main.go":       main,
+		"config_gen.go": string(src),
+	}
+	for n, s := range files {
+		err := os.WriteFile(filepath.Join(dir, n), []byte(s), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(gobin, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+}
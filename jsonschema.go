@@ -0,0 +1,172 @@
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema converts specification into JSON Schema document which
+// describes JSON representation of the configuration.
+//
+// Configuration and every block are represented as JSON objects.
+// Property is an object member with its value. Repeated properties and
+// blocks are arrays of values. Duration is a string in time.ParseDuration()
+// format and string list is an array of strings. Properties and blocks
+// with star-pattern names are described with patternProperties, so
+// star-blocks become object members named after the block. Since every
+// matching patternProperties entry applies to the member, patterns
+// exclude explicit names and more specific patterns with a negative
+// lookahead, so every member is checked against the specification the
+// parser would choose for it. Labeled
+// blocks are nested objects keyed by label values, one level per label.
+func JSONSchema(spec *Spec) ([]byte, error) {
+	s := blockSchema(spec.Properties, spec.Blocks, spec.Strict)
+	s["$schema"] = jsonSchemaDialect
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func blockSchema(props []*PropertySpec, blocks []*BlockSpec,
+	strict bool) map[string]any {
+
+	properties := map[string]any{}
+	stars := map[string]any{}
+	var required []string
+
+	add := func(name string, s map[string]any, repeat bool, require bool) {
+		if repeat {
+			s = map[string]any{"type": "array", "items": s}
+			if require {
+				s["minItems"] = 1
+			}
+		}
+		if strings.Contains(name, "*") {
+			stars[name] = s
+		} else {
+			properties[name] = s
+			if require {
+				required = append(required, name)
+			}
+		}
+	}
+	for _, p := range props {
+		s := propertySchema(p)
+		if p.Description != "" {
+			s["description"] = p.Description
+		}
+		if p.Default != nil {
+			s["default"] = jsonValue(p.Default)
+		}
+		if p.Deprecated != "" {
+			s["deprecated"] = true
+		}
+		add(p.Name, s, p.Repeat, p.Require)
+	}
+	for _, b := range blocks {
+		s := blockSchema(b.Properties, b.Blocks, b.Strict)
+		if b.Description != "" {
+			s["description"] = b.Description
+		}
+		if b.Deprecated != "" {
+			s["deprecated"] = true
+		}
+		for range b.Labels {
+			s = map[string]any{
+				"type":                 "object",
+				"additionalProperties": s,
+			}
+		}
+		add(b.Name, s, b.Repeat, b.Require)
+	}
+
+	patterns := map[string]any{}
+	for name, s := range stars {
+		patterns[schemaPattern(name, properties, stars)] = s
+	}
+
+	s := map[string]any{"type": "object"}
+	if len(properties) > 0 {
+		s["properties"] = properties
+	}
+	if len(patterns) > 0 {
+		s["patternProperties"] = patterns
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	if strict {
+		s["additionalProperties"] = false
+	}
+
+	return s
+}
+
+// schemaPattern converts star-pattern name into regular expression
+// which does not match explicit names matched by the pattern and names
+// matched by more specific patterns. Pattern which is greater than the
+// other one is more specific, as it is chosen by the parser.
+func schemaPattern(name string, properties map[string]any,
+	stars map[string]any) string {
+
+	var excl []string
+	for n := range properties {
+		if MatchName(n, name) {
+			excl = append(excl, regexp.QuoteMeta(n)+"$")
+		}
+	}
+	for n := range stars {
+		if n > name {
+			excl = append(excl, namePattern(n)[1:])
+		}
+	}
+	p := namePattern(name)
+	if len(excl) == 0 {
+		return p
+	}
+	sort.Strings(excl)
+
+	return "^(?!" + strings.Join(excl, "|") + ")" + p[1:]
+}
+
+func propertySchema(spec *PropertySpec) map[string]any {
+	switch spec.Type {
+	case TypeBool:
+		return map[string]any{"type": "boolean"}
+	case TypeDuration:
+		return map[string]any{"type": "string"}
+	case TypeInt:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case TypeString:
+		s := map[string]any{"type": "string"}
+		if len(spec.Enum) > 0 {
+			s["enum"] = spec.Enum
+		}
+		return s
+	case TypeStringList:
+		items := map[string]any{"type": "string"}
+		if len(spec.Enum) > 0 {
+			items["enum"] = spec.Enum
+		}
+		return map[string]any{
+			"type":     "array",
+			"items":    items,
+			"minItems": 1,
+		}
+	default:
+		panic("unsupported Type")
+	}
+}
+
+// jsonValue converts property value into its JSON representation.
+func jsonValue(v any) any {
+	if d, ok := v.(time.Duration); ok {
+		return d.String()
+	}
+
+	return v
+}
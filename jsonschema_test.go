@@ -0,0 +1,246 @@
+package config
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSchema(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port", Require: true},
+			&PropertySpec{Type: TypeString, Name: "host", Repeat: true,
+				Description: "Host name."},
+			&PropertySpec{Type: TypeDuration, Name: "timeout",
+				Default: time.Minute},
+			&PropertySpec{Type: TypeBool, Name: "opt.*"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name:   "*",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeStringList,
+						Name: "tags"},
+				},
+			},
+		},
+		Strict: true,
+	}
+	exp := `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "additionalProperties": false,
+  "patternProperties": {
+    "^(?!host$|opt\\..*$|port$|timeout$).*$": {
+      "items": {
+        "properties": {
+          "tags": {
+            "items": {
+              "type": "string"
+            },
+            "minItems": 1,
+            "type": "array"
+          }
+        },
+        "type": "object"
+      },
+      "type": "array"
+    },
+    "^opt\\..*$": {
+      "type": "boolean"
+    }
+  },
+  "properties": {
+    "host": {
+      "items": {
+        "description": "Host name.",
+        "type": "string"
+      },
+      "type": "array"
+    },
+    "port": {
+      "minimum": 0,
+      "type": "integer"
+    },
+    "timeout": {
+      "default": "1m0s",
+      "type": "string"
+    }
+  },
+  "required": [
+    "port"
+  ],
+  "type": "object"
+}`
+	act, err := JSONSchema(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(act) != exp {
+		t.Fatalf("%s != %s", exp, act)
+	}
+	if !json.Valid(act) {
+		t.Fatal()
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port", Require: true},
+			&PropertySpec{Type: TypeString, Name: "host",
+				Enum: []string{"a", "b"}},
+			&PropertySpec{Type: TypeBool, Name: "opt.*"},
+			&PropertySpec{Type: TypeInt, Name: "foo.*"},
+			&PropertySpec{Type: TypeString, Name: "foo.bar*"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name:   "*",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeStringList,
+						Name: "tags"},
+				},
+			},
+		},
+		Strict: true,
+	}
+	b, err := JSONSchema(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(b, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	test := func(doc string, valid bool) {
+		var v any
+		if err := json.Unmarshal([]byte(doc), &v); err != nil {
+			t.Fatal(err)
+		}
+		if validateSchema(schema, v) != valid {
+			t.Fatalf("%s: valid expected to be %v", doc, valid)
+		}
+	}
+	test(`{"port": 80}`, true)
+	test(`{"port": 80, "host": "a", "opt.x": true, "foo.x": 1,
+                "foo.bar": "s", "sda": [{"tags": ["x"]}]}`, true)
+	test(`{}`, false)
+	test(`{"port": -1}`, false)
+	test(`{"port": "80"}`, false)
+	test(`{"port": 80, "host": "c"}`, false)
+	test(`{"port": 80, "opt.x": 1}`, false)
+	test(`{"port": 80, "optx": true}`, false)
+	test(`{"port": 80, "foo.bar": 1}`, false)
+	test(`{"port": 80, "sda": {"tags": ["x"]}}`, false)
+}
+
+// validateSchema checks JSON value against the subset of JSON Schema
+// generated by JSONSchema.
+func validateSchema(s map[string]any, v any) bool {
+	switch s["type"] {
+	case "object":
+		o, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		req, _ := s["required"].([]any)
+		for _, r := range req {
+			if _, ok := o[r.(string)]; !ok {
+				return false
+			}
+		}
+		props, _ := s["properties"].(map[string]any)
+		patterns, _ := s["patternProperties"].(map[string]any)
+		for k, mv := range o {
+			var schemas []any
+			if ps, ok := props[k]; ok {
+				schemas = append(schemas, ps)
+			}
+			for p, ps := range patterns {
+				if matchSchemaPattern(p, k) {
+					schemas = append(schemas, ps)
+				}
+			}
+			if len(schemas) == 0 {
+				switch a := s["additionalProperties"].(type) {
+				case bool:
+					if !a {
+						return false
+					}
+				case map[string]any:
+					schemas = append(schemas, a)
+				}
+			}
+			for _, ms := range schemas {
+				if !validateSchema(ms.(map[string]any), mv) {
+					return false
+				}
+			}
+		}
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			return false
+		}
+		if m, ok := s["minItems"].(float64); ok && float64(len(a)) < m {
+			return false
+		}
+		for _, i := range a {
+			if !validateSchema(s["items"].(map[string]any), i) {
+				return false
+			}
+		}
+	case "integer":
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			return false
+		}
+		if m, ok := s["minimum"].(float64); ok && n < m {
+			return false
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return false
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return false
+		}
+		if enum, ok := s["enum"].([]any); ok {
+			for _, e := range enum {
+				if e == str {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchSchemaPattern matches the name against patternProperties regular
+// expression emulating leading negative lookahead, which is not
+// supported by regexp package.
+func matchSchemaPattern(pattern string, name string) bool {
+	if !strings.HasPrefix(pattern, "^(?!") {
+		return regexp.MustCompile(pattern).MatchString(name)
+	}
+	i := 4
+	for ; pattern[i] != ')'; i++ {
+		if pattern[i] == '\\' {
+			i++
+		}
+	}
+	excl := regexp.MustCompile("^(?:" + pattern[4:i] + ")")
+
+	return !excl.MatchString(name) &&
+		regexp.MustCompile("^"+pattern[i+1:]).MatchString(name)
+}
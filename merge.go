@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+)
+
+// MergeMode defines how repeated property is merged.
+type MergeMode int
+
+const (
+	// Properties of the later configuration replace all properties
+	// with the same name of the earlier ones.
+	MergeReplace MergeMode = iota
+	// Properties of the later configuration are appended to the
+	// properties with the same name of the earlier ones.
+	MergeAppend
+)
+
+// Merge combines configurations according to the specification, every
+// next configuration takes precedence over previous ones. It is useful
+// for layered configuration, like system-wide file, user file and local
+// overrides. Configurations are usually parsed with Partial option, so
+// required properties and blocks are checked for the merged result only.
+//
+// Non-repeated properties of the later configuration override earlier
+// ones. Repeated properties are replaced or appended depending on
+// PropertySpec.Merge. Non-repeated blocks with the same name and labels
+// are merged recursively, repeated blocks are appended. Property.Source
+// can be used to find out which configuration a property comes from.
+// Given configurations are not modified.
+func Merge(spec *Spec, cfgs ...*Config) (*Config, error) {
+	rs := &BlockSpec{
+		Name:       rootBlock,
+		Properties: spec.Properties,
+		Blocks:     spec.Blocks,
+	}
+	b := &Block{Name: rootBlock}
+	for _, c := range cfgs {
+		if c != nil {
+			mergeBlock(rs, b, &Block{
+				Properties: c.Properties,
+				Blocks:     c.Blocks,
+			})
+		}
+	}
+	if err := checkRequired(rs, b, ""); err != nil {
+		return nil, err
+	}
+
+	return &Config{b.Properties, b.Blocks}, nil
+}
+
+// mergeBlock merges src block into the dst one.
+func mergeBlock(spec *BlockSpec, dst *Block, src *Block) {
+	replaced := map[string]bool{}
+	for _, p := range src.Properties {
+		s := findProperty(spec.Properties, p.Name)
+		if s != nil && s.Repeat && s.Merge == MergeAppend {
+			dst.Properties = append(dst.Properties, p)
+			continue
+		}
+		if !replaced[p.Name] {
+			var ps []*Property
+			for _, dp := range dst.Properties {
+				if dp.Name != p.Name {
+					ps = append(ps, dp)
+				}
+			}
+			dst.Properties = ps
+			replaced[p.Name] = true
+		}
+		dst.Properties = append(dst.Properties, p)
+	}
+
+	for _, b := range src.Blocks {
+		s := findBlock(spec.Blocks, b.Name)
+		if s == nil || !s.Repeat {
+			if d := dst.BlockLabeled(b.Name, b.Labels...); d != nil {
+				if s == nil {
+					s = &BlockSpec{}
+				}
+				mergeBlock(s, d, b)
+				continue
+			}
+		}
+		dst.Blocks = append(dst.Blocks, copyBlock(b))
+	}
+}
+
+// copyBlock returns copy of the block which can be modified without
+// modification of the original block. Properties are not copied.
+func copyBlock(b *Block) *Block {
+	c := &Block{
+		Name:       b.Name,
+		Properties: append([]*Property(nil), b.Properties...),
+		Labels:     b.Labels,
+		Source:     b.Source,
+		Pos:        b.Pos,
+	}
+	for _, cb := range b.Blocks {
+		c.Blocks = append(c.Blocks, copyBlock(cb))
+	}
+
+	return c
+}
+
+// checkRequired checks that all required properties and blocks are
+// present in the block and its nested blocks.
+func checkRequired(spec *BlockSpec, b *Block, path string) error {
+	for _, s := range spec.Properties {
+		if s.Require && b.Property(s.Name) == nil {
+			return fmt.Errorf("missing required property `%s`",
+				joinPath(path, s.Name))
+		}
+	}
+	for _, s := range spec.Blocks {
+		if s.Require && b.Block(s.Name) == nil {
+			return fmt.Errorf("missing required block `%s`",
+				joinPath(path, s.Name))
+		}
+	}
+	for _, c := range b.Blocks {
+		s := findBlock(spec.Blocks, c.Name)
+		if s == nil {
+			continue
+		}
+		if err := checkRequired(s, c, joinPath(path, c.Name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SourceDefault is the Property.Source of properties created from
+// PropertySpec.Default values.
+const SourceDefault = "<default>"
+
+// Defaults returns configuration which consists of default values of
+// properties described by the specification. It can be used as the
+// first configuration passed to Merge. Blocks which contain properties
+// with default values are created too. Properties are selected the same
+// way as for flags, see overridable.
+func Defaults(spec *Spec) *Config {
+	root := &Block{Name: rootBlock}
+	overridable(nil, spec.Properties, spec.Blocks,
+		func(names []string, p *PropertySpec) {
+			if p.Default != nil {
+				setProperty(root, names, p, p.Default,
+					SourceDefault)
+			}
+		})
+
+	return &Config{root.Properties, root.Blocks}
+}
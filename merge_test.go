@@ -0,0 +1,146 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port", Require: true},
+			&PropertySpec{Type: TypeString, Name: "include",
+				Repeat: true, Merge: MergeAppend},
+			&PropertySpec{Type: TypeString, Name: "listen",
+				Repeat: true},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "db",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host",
+						Require: true},
+					&PropertySpec{Type: TypeInt, Name: "pool"},
+				},
+			},
+			&BlockSpec{
+				Name:   "hook",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "cmd"},
+				},
+			},
+		},
+	}
+	dir := t.TempDir()
+	parse := func(name string, s string) *Config {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(s), 0644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := ParseFile(spec, file, Partial())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	system := parse("system.conf", `
+                port = 80
+                include = "a"
+                listen = "0.0.0.0"
+                listen = "::"
+                db {
+                    host = "db.local"
+                    pool = 10
+                }
+                hook { cmd = "one" }
+        `)
+	user := parse("user.conf", `
+                port = 8080
+                include = "b"
+                listen = "127.0.0.1"
+                db {
+                    pool = 20
+                }
+                hook { cmd = "two" }
+        `)
+	systemFile := filepath.Join(dir, "system.conf")
+	userFile := filepath.Join(dir, "user.conf")
+
+	cfg, err := Merge(spec, system, nil, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, &Config{
+		[]*Property{
+			&Property{Type: TypeString, Name: "include", Value: "a",
+				Source: systemFile},
+			&Property{Type: TypeInt, Name: "port", Value: 8080,
+				Source: userFile},
+			&Property{Type: TypeString, Name: "include", Value: "b",
+				Source: userFile},
+			&Property{Type: TypeString, Name: "listen",
+				Value: "127.0.0.1", Source: userFile},
+		},
+		[]*Block{
+			&Block{
+				Name:   "db",
+				Source: systemFile,
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "host",
+						Value: "db.local", Source: systemFile},
+					&Property{Type: TypeInt, Name: "pool",
+						Value: 20, Source: userFile},
+				},
+			},
+			&Block{
+				Name:   "hook",
+				Source: systemFile,
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "cmd",
+						Value: "one", Source: systemFile},
+				},
+			},
+			&Block{
+				Name:   "hook",
+				Source: userFile,
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "cmd",
+						Value: "two", Source: userFile},
+				},
+			},
+		},
+	}, stripPos(cfg))
+	// Source configurations are not modified.
+	assert(t, 10, system.Block("db").Int("pool"))
+	assert(t, 1, len(system.AllBlocks("hook")))
+
+	// Sources of configurations parsed from strings are named with
+	// Source option.
+	a, err := Parse(spec, "port = 1\ndb { host = \"a\" }", Source("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Parse(spec, "db { pool = 2 }", Partial(), Source("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Merge(spec, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, "a", cfg.Property("port").Source)
+	assert(t, "a", cfg.Block("db").Source)
+	assert(t, "a", cfg.Block("db").Property("host").Source)
+	assert(t, "b", cfg.Block("db").Property("pool").Source)
+
+	_, err = Merge(spec, user)
+	if err == nil || err.Error() != "missing required property `db.host`" {
+		t.Fatal(err)
+	}
+	_, err = Merge(spec)
+	if err == nil || err.Error() != "missing required property `port`" {
+		t.Fatal(err)
+	}
+}
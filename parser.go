@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +29,23 @@ const (
 	TypeStringList
 )
 
+var typeNames = map[Type]string{
+	TypeBool:       "bool",
+	TypeDuration:   "duration",
+	TypeInt:        "int",
+	TypeString:     "string",
+	TypeStringList: "stringlist",
+}
+
+// String returns name of the type as it is used in specification files.
+func (t Type) String() string {
+	if n, ok := typeNames[t]; ok {
+		return n
+	}
+
+	return "Type(" + strconv.Itoa(int(t)) + ")"
+}
+
 // Property value custom parser function.
 // Also can be used to validate parsed value.
 type Parser func(any) (any, error)
@@ -43,6 +63,27 @@ type PropertySpec struct {
 	Repeat  bool
 	Require bool
 	Parser  Parser
+	// Human-readable description used in generated documentation.
+	Description string
+	// Example value in configuration file syntax, like `"localhost"`.
+	Example string
+	// Value the application assumes when property is not set. Parser
+	// does not fill it in, but Defaults turns defaults into
+	// configuration which can be merged under the parsed one with
+	// Merge, NewFlags shows it as the flag default value, generated
+	// Go accessors return it and documentation mentions it.
+	Default any
+	// List of allowed values for string and string list properties.
+	Enum []string
+	// Old names of the property. Property defined with an alias is
+	// stored under the canonical Name and deprecation warning is
+	// reported. Aliases are not supported for star-names.
+	Aliases []string
+	// Deprecation message. If set, warning is reported when property
+	// is used.
+	Deprecated string
+	// Defines how repeated property is merged by Merge.
+	Merge MergeMode
 }
 
 // Specification descriptor for block of properties.
@@ -75,30 +116,133 @@ type BlockSpec struct {
 	Require    bool
 	Properties []*PropertySpec
 	Blocks     []*BlockSpec
-	Strict     bool
+	// In strict mode unsupported properties and blocks are errors,
+	// otherwise they are ignored and reported as warnings.
+	Strict bool
+	// Human-readable description used in generated documentation.
+	Description string
+	// Example block in configuration file syntax.
+	Example string
+	// Old names of the block. Block defined with an alias is stored
+	// under the canonical Name and deprecation warning is reported.
+	// Aliases are not supported for star-names.
+	Aliases []string
+	// Deprecation message. If set, warning is reported when block is
+	// used.
+	Deprecated string
+	// Names of the block labels. Labeled block is defined with the
+	// given number of strings between the block name and the opening
+	// brace, like `upstream "backend-1" { ... }`. Blocks with the same
+	// name and different labels are not considered duplicates.
+	Labels []string
 }
 
 type Spec struct {
 	Properties []*PropertySpec
 	Blocks     []*BlockSpec
-	Strict     bool
+	// In strict mode unsupported properties and blocks are errors,
+	// otherwise they are ignored and reported as warnings.
+	Strict bool
 }
 
 const (
 	rootBlock = ""
+	// Keyword which starts block template declaration.
+	templateKeyword = "template"
 )
 
-func ParseFile(spec *Spec, file string) (*Config, error) {
+// Option configures parsing.
+type Option func(*parser)
+
+// OnWarning sets callback which is called for every non-fatal problem
+// found in the input, like unsupported property ignored in non-strict
+// mode.
+func OnWarning(f func(*Warning)) Option {
+	return func(p *parser) {
+		p.onWarning = f
+	}
+}
+
+// Source sets name of the parsed input. It is recorded in Property.Source
+// and Block.Source and reported in errors, so configurations parsed from
+// strings can be told apart after Merge. ParseFile uses the file name by
+// default.
+func Source(name string) Option {
+	return func(p *parser) {
+		p.file = name
+	}
+}
+
+// Partial disables required properties and blocks checks. It is useful
+// for configuration files which are merged with others by Merge, so
+// required properties can be defined in any of them.
+func Partial() Option {
+	return func(p *parser) {
+		p.partial = true
+	}
+}
+
+// CollectErrors enables error-recovering parse mode. Instead of stopping
+// at the first problem parser skips to the next property or block and
+// continues, so all problems found in the input are returned at once as
+// ErrorList.
+func CollectErrors() Option {
+	return func(p *parser) {
+		p.collect = true
+	}
+}
+
+type parser struct {
+	t         *Tokenizer
+	file      string
+	collect   bool
+	partial   bool
+	errs      ErrorList
+	onWarning func(*Warning)
+	// Block templates visible in the current block, one scope per
+	// nesting level.
+	templates []map[string]*template
+	// Problems already reported, keyed by offset and message.
+	reported map[string]bool
+}
+
+// template is a block template declaration. Template body is not parsed
+// at declaration time since its properties and blocks can be validated
+// only against specification of the inheriting block. Body is parsed
+// every time the template is used instead, but every problem found in
+// the body is reported once.
+type template struct {
+	name string
+	// Position of the template name.
+	pos Position
+	// Template is inherited by a block or other template.
+	used bool
+	// Position right after the opening brace of the template body.
+	body Position
+	// Template this template inherits.
+	base *template
+	// Templates visible at the template declaration.
+	scope []map[string]*template
+}
+
+func ParseFile(spec *Spec, file string, opts ...Option) (*Config, error) {
 	d, err := os.ReadFile(file)
 	if err != nil {
 		return nil, err
 	}
 
-	return Parse(spec, string(d))
+	return parse(spec, string(d), file, opts)
+}
+
+func Parse(spec *Spec, s string, opts ...Option) (*Config, error) {
+	return parse(spec, s, "", opts)
 }
 
-func Parse(spec *Spec, s string) (*Config, error) {
-	t := NewTokenizer(s)
+func parse(spec *Spec, s string, file string, opts []Option) (*Config, error) {
+	p := &parser{t: NewTokenizer(s), file: file}
+	for _, o := range opts {
+		o(p)
+	}
 	rs := &BlockSpec{
 		Name:       rootBlock,
 		Repeat:     false,
@@ -106,224 +250,841 @@ func Parse(spec *Spec, s string) (*Config, error) {
 		Blocks:     spec.Blocks,
 		Strict:     spec.Strict,
 	}
-	b, err := parseBlock(t, rs.Name, rs)
+	b, err := p.parseBlock(rs.Name, p.t.Pos(), rs, nil)
 	if err != nil {
 		return nil, err
 	}
+	if len(p.errs) > 0 {
+		return nil, p.errs
+	}
 
 	return &Config{b.Properties, b.Blocks}, nil
 }
 
-func parseBlock(t *Tokenizer, name string, spec *BlockSpec) (*Block, error) {
+// fail records parse error. It returns the error back if parsing should
+// be stopped, or nil if parser should recover and continue.
+func (p *parser) fail(err error) error {
+	e := err.(*ParseError)
+	e.File = p.file
+	if !p.collect {
+		return e
+	}
+	if !p.seen(e.Offset, e.Msg) {
+		p.errs = append(p.errs, e)
+	}
+
+	return nil
+}
+
+// seen reports whether problem with the message was already reported at
+// the given offset and remembers it otherwise. Template body is parsed
+// for every block which inherits it, so the same problem can be found
+// more than once.
+func (p *parser) seen(off int, msg string) bool {
+	k := strconv.Itoa(off) + ":" + msg
+	if p.reported[k] {
+		return true
+	}
+	if p.reported == nil {
+		p.reported = map[string]bool{}
+	}
+	p.reported[k] = true
+
+	return false
+}
+
+// warn reports warning at the given position.
+func (p *parser) warn(pos Position, code ErrorCode, suggestions []string,
+	format string, args ...any) {
+
+	msg := fmt.Sprintf(format, args...)
+	if p.onWarning == nil || p.seen(pos.Offset, msg) {
+		return
+	}
+	p.onWarning(&Warning{
+		File:        p.file,
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Offset:      pos.Offset,
+		Code:        code,
+		Msg:         msg,
+		Suggestions: suggestions,
+	})
+}
+
+// parseBlock parses block body. Start is position of the block name, it is
+// used to report block-level errors like missing required property.
+// If base template is given, properties and blocks of the template are
+// inherited and overridden by the ones defined in the block body.
+func (p *parser) parseBlock(name string, start Position, spec *BlockSpec,
+	base *template) (*Block, error) {
+
+	var props []*Property
+	var blocks []*Block
+	var invalid []string
+	if base != nil {
+		b, inv, err := p.expand(base, name, spec)
+		if err != nil {
+			return nil, err
+		}
+		props, blocks, invalid = b.Properties, b.Blocks, inv
+	}
+	b, inv, err := p.parseBody(name, spec)
+	if err != nil {
+		return nil, err
+	}
+	props, blocks = override(props, blocks, b.Properties, b.Blocks)
+	invalid = append(invalid, inv...)
+
+	if p.partial {
+		return &Block{Name: name, Properties: props, Blocks: blocks}, nil
+	}
+	t := p.t
+	for _, s := range spec.Properties {
+		if s.Require {
+			i := contains(len(props), func(i int) bool {
+				return props[i].Name == s.Name
+			})
+			j := contains(len(invalid), func(i int) bool {
+				return invalid[i] == s.Name
+			})
+			if i == -1 && j == -1 {
+				err := p.fail(newError(t, start,
+					CodeMissingProperty,
+					"missing required property `%s`",
+					s.Name))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, s := range spec.Blocks {
+		if s.Require {
+			i := contains(len(blocks), func(i int) bool {
+				return blocks[i].Name == s.Name
+			})
+			if i == -1 {
+				err := p.fail(newError(t, start,
+					CodeMissingBlock,
+					"missing required block `%s`",
+					s.Name))
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return &Block{Name: name, Properties: props, Blocks: blocks}, nil
+}
+
+// parseBody parses properties and blocks of the block body up to the
+// closing brace. Specification requirements which depend on the whole
+// block, like required properties, are not checked. Names of properties
+// with invalid values are returned too, so they are not reported as
+// missing in collect mode.
+func (p *parser) parseBody(name string,
+	spec *BlockSpec) (*Block, []string, error) {
+
+	t := p.t
 	var props []*Property
 	var blocks []*Block
+	var invalid []string
 	var closed bool = name == rootBlock
+	p.templates = append(p.templates, map[string]*template{})
 
 	for t.HasNext() {
 		n, err := t.Next()
 		if err != nil {
-			return nil, newError(t.Line(), err.Error())
+			if err := p.fail(err); err != nil {
+				return nil, nil, err
+			}
+			continue
 		}
 		if name != rootBlock && n.Name == NameBlockEnd {
 			closed = true
 			break
 		}
 		if n.Name != NameIdent {
-			return nil, newError(t.Line(), "identifier token expected")
+			err := p.fail(newError(t, n.Start, CodeSyntax,
+				"identifier token expected"))
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
 		}
 
 		op, err := t.Next()
 		if err != nil {
-			return nil, newError(t.Line(), err.Error())
+			if err := p.fail(err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if n.Value == templateKeyword && op.Name == NameIdent {
+			if err := p.parseTemplate(op); err != nil {
+				if err := p.fail(err); err != nil {
+					return nil, nil, err
+				}
+				p.sync(op.Start.Line)
+			}
+			continue
+		}
+		// Block labels are string tokens between the name and `{`.
+		var labels []string
+		for op.Name == NameString && t.HasNext() {
+			labels = append(labels, op.Value)
+			op, err = t.Next()
+			if err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if err := p.fail(err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		var base *template
+		if op.Name == NameColon {
+			base, op, err = p.parseBase()
+			if err != nil {
+				if err := p.fail(err); err != nil {
+					return nil, nil, err
+				}
+				p.sync(n.Start.Line)
+				continue
+			}
+		}
+		if (labels != nil || base != nil) && op.Name != NameBlockStart {
+			err := p.fail(newError(t, op.Start, CodeSyntax,
+				"`{` expected"))
+			if err != nil {
+				return nil, nil, err
+			}
+			if op.Name == NameBlockEnd ||
+				op.Start.Line != n.Start.Line {
+
+				t.Unread()
+			} else {
+				p.sync(op.Start.Line)
+			}
+			continue
 		}
 
 		switch op.Name {
 		case NameEq:
 			if !t.HasNext() {
-				return nil, newError(t.Line(), "value expected")
+				err := p.fail(newError(t, t.Pos(), CodeSyntax,
+					"value expected"))
+				if err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			v, err := t.Next()
 			if err != nil {
-				return nil, newError(t.Line(), err.Error())
+				if err := p.fail(err); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			s := findProperty(spec.Properties, n.Value)
 			if s == nil {
+				p.skipList()
 				if spec.Strict {
-					return nil, newError(t.Line(),
-						"unsupported property: %s", n.Value)
+					sg := suggest(n.Value,
+						propertyNames(spec.Properties))
+					e := newError(t, n.Start,
+						CodeUnsupportedProperty,
+						"unsupported property: %s%s", n.Value,
+						didYouMean(sg))
+					e.Suggestions = sg
+					if err := p.fail(e); err != nil {
+						return nil, nil, err
+					}
 				} else {
-					continue
+					sg := suggest(n.Value,
+						propertyNames(spec.Properties))
+					p.warn(n.Start, CodeUnsupportedProperty, sg,
+						"unsupported property ignored: %s%s",
+						n.Value, didYouMean(sg))
 				}
+				continue
 			}
+			pname := p.canonical("property", n, s.Name, s.Aliases,
+				s.Deprecated)
+			// Invalid property value is still parsed in collect
+			// mode, so errors in the value are reported too.
+			valid := true
 			i := contains(len(props), func(i int) bool {
-				return props[i].Name == n.Value
+				return props[i].Name == pname
 			})
 			if i != -1 {
 				if !s.Repeat {
-					return nil, newError(t.Line(),
+					err := p.fail(newError(t, n.Start,
+						CodeDuplicateProperty,
 						"property `%s` already defined",
-						n.Value)
-
+						pname))
+					if err != nil {
+						return nil, nil, err
+					}
+					valid = false
 				}
 			}
 
 			var val any
 			switch s.Type {
-			case TypeBool:
-				if v.Name == NameIdent && v.Value == "true" {
-					val = true
-				} else if v.Name == NameIdent && v.Value == "false" {
-					val = false
-				} else {
-					return nil, newError(t.Line(),
-						"invalid boolean value")
-				}
-			case TypeDuration:
-				if v.Name != NameIdent {
-					return nil, newError(t.Line(),
-						"duration value expected")
-				}
-				d, err := time.ParseDuration(v.Value)
+			case TypeBool, TypeDuration, TypeInt, TypeString:
+				val, err = parseValue(s.Type, v)
 				if err != nil {
-					return nil, newError(t.Line(),
-						"invalid duration value")
-				}
-				val = d
-			case TypeInt:
-				if v.Name != NameIdent {
-					return nil, newError(t.Line(),
-						"integer value expected")
-				}
-				i, err := strconv.Atoi(v.Value)
-				if err != nil {
-					return nil, newError(t.Line(),
-						"invalid integer value")
-				}
-				val = i
-			case TypeString:
-				if v.Name != NameString {
-					return nil, newError(t.Line(),
-						"string value expected")
-				}
-				val = v.Value
-			case TypeStringList:
-				// TODO: Add empty list support.
-				if v.Name != NameString {
-					return nil, newError(t.Line(),
-						"strings list expected")
-				}
-				var lst []string
-
-				lst = append(lst, v.Value)
-				for {
-					if !t.HasNext() {
-						break
-					}
-					tk, err := t.Next()
-					if err != nil {
-						return nil, newError(t.Line(),
-							err.Error())
-					}
-					if tk.Name != NameComma {
-						t.Unread()
-						break
-					}
-					if !t.HasNext() {
-						return nil, newError(t.Line(),
-							"unexpected EOF")
-					}
-					tk, err = t.Next()
+					err := p.fail(wrapError(t, v.Start,
+						CodeType, err))
 					if err != nil {
-						return nil, newError(t.Line(),
-							err.Error())
+						return nil, nil, err
 					}
-					if tk.Name != NameString {
-						return nil, newError(t.Line(),
-							"string value expected")
+					invalid = append(invalid, pname)
+					continue
+				}
+			case TypeStringList:
+				val, err = p.parseList(v)
+				if err != nil {
+					if err := p.fail(err); err != nil {
+						return nil, nil, err
 					}
-					lst = append(lst, tk.Value)
+					p.sync(v.Start.Line)
+					invalid = append(invalid, pname)
+					continue
 				}
-				val = lst
 			default:
 				panic("unsupported Type")
 			}
 
+			if bad := checkEnum(s, val); bad != "" {
+				sg := suggest(bad, s.Enum)
+				e := newError(t, v.Start, CodeInvalidValue,
+					"invalid value `%s`, expected one of: %s%s",
+					bad, strings.Join(s.Enum, ", "),
+					didYouMean(sg))
+				e.Suggestions = sg
+				if err := p.fail(e); err != nil {
+					return nil, nil, err
+				}
+				invalid = append(invalid, pname)
+				continue
+			}
+
 			if s.Parser != nil {
 				val, err = s.Parser(val)
 				if err != nil {
-					return nil, newError(t.Line(),
-						err.Error())
+					err := p.fail(wrapError(t, v.Start,
+						CodeInvalidValue, err))
+					if err != nil {
+						return nil, nil, err
+					}
+					invalid = append(invalid, pname)
+					continue
 				}
 			}
 
-			props = append(props, &Property{
-				Type:  s.Type,
-				Name:  n.Value,
-				Value: val,
-			})
+			if valid {
+				props = append(props, &Property{
+					Type:   s.Type,
+					Name:   pname,
+					Value:  val,
+					Source: p.file,
+					Pos:    n.Start,
+				})
+			}
 		case NameBlockStart:
 			s := findBlock(spec.Blocks, n.Value)
 			if s == nil {
-				return nil, newError(t.Line(),
-					"unsupported block: %s", n.Value)
+				sg := suggest(n.Value, blockNames(spec.Blocks))
+				if spec.Strict {
+					e := newError(t, n.Start,
+						CodeUnsupportedBlock,
+						"unsupported block: %s%s", n.Value,
+						didYouMean(sg))
+					e.Suggestions = sg
+					if err := p.fail(e); err != nil {
+						return nil, nil, err
+					}
+				} else {
+					p.warn(n.Start, CodeUnsupportedBlock, sg,
+						"unsupported block ignored: %s%s",
+						n.Value, didYouMean(sg))
+				}
+				p.skipBlock()
+				continue
+			}
+			bname := p.canonical("block", n, s.Name, s.Aliases,
+				s.Deprecated)
+			valid := true
+			if len(labels) != len(s.Labels) {
+				var e *ParseError
+				if len(s.Labels) == 0 {
+					e = newError(t, n.Start, CodeLabels,
+						"block `%s` does not accept labels",
+						bname)
+				} else {
+					e = newError(t, n.Start, CodeLabels,
+						"block `%s` expects %d label(s): %s",
+						bname, len(s.Labels),
+						strings.Join(s.Labels, ", "))
+				}
+				if err := p.fail(e); err != nil {
+					return nil, nil, err
+				}
+				valid = false
 			}
-			b, err := parseBlock(t, n.Value, s)
+			b, err := p.parseBlock(bname, n.Start, s, base)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
+			if !valid {
+				continue
+			}
+			b.Labels = labels
+			b.Source = p.file
+			b.Pos = n.Start
 			i := contains(len(blocks), func(i int) bool {
-				return blocks[i].Name == n.Value
+				return blocks[i].Name == bname &&
+					equalLabels(blocks[i].Labels, labels)
 			})
 			if i != -1 {
 				if !s.Repeat {
-					return nil, newError(t.Line(),
+					err := p.fail(newError(t, n.Start,
+						CodeDuplicateBlock,
 						"block `%s` already defined",
-						n.Value)
+						blockTitle(bname, labels)))
+					if err != nil {
+						return nil, nil, err
+					}
+					continue
 				}
 			}
 			blocks = append(blocks, b)
 		default:
+			// Point to the end of the line if operator is missing.
+			pos := op.Start
+			if op.Start.Line != n.Start.Line {
+				pos = n.End
+			}
+			var err error
 			ps := findProperty(spec.Properties, n.Value)
+			bs := findBlock(spec.Blocks, n.Value)
 			if ps != nil {
-				return nil, newError(t.Line(), "`=` expected")
+				err = newError(t, pos, CodeSyntax, "`=` expected")
+			} else if bs != nil {
+				err = newError(t, pos, CodeSyntax, "`{` expected")
+			} else {
+				err = newError(t, pos, CodeSyntax,
+					"`=` or `{` expected")
 			}
-			bs := findBlock(spec.Blocks, n.Value)
-			if bs != nil {
-				return nil, newError(t.Line(), "`{` expected")
+			if err := p.fail(err); err != nil {
+				return nil, nil, err
+			}
+			if op.Name == NameBlockEnd || op.Start.Line != n.Start.Line {
+				// Probably missing value, so next token can
+				// start a new property or block.
+				t.Unread()
+			} else {
+				p.sync(op.Start.Line)
 			}
-			return nil, newError(t.Line(), "`=` or `{` expected")
 		}
 	}
 	if !closed {
-		return nil, newError(t.Line(), "`}` expected")
+		err := p.fail(newError(t, t.Pos(), CodeSyntax, "`}` expected"))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := p.checkUnused(spec); err != nil {
+		return nil, nil, err
 	}
 
-	for _, s := range spec.Properties {
-		if s.Require {
-			i := contains(len(props), func(i int) bool {
-				return props[i].Name == s.Name
-			})
-			if i == -1 {
-				return nil, newError(t.Line(),
-					"missing required property `%s`",
-					s.Name)
+	p.templates = p.templates[:len(p.templates)-1]
+
+	return &Block{Name: name, Properties: props, Blocks: blocks},
+		invalid, nil
+}
+
+// parseTemplate parses block template declaration which starts with the
+// already read template name token.
+func (p *parser) parseTemplate(name *Token) error {
+	t := p.t
+	var base *template
+	op, err := p.next()
+	if err != nil {
+		return err
+	}
+	if op.Name == NameColon {
+		base, op, err = p.parseBase()
+		if err != nil {
+			return err
+		}
+	}
+	if op.Name != NameBlockStart {
+		return newError(t, op.Start, CodeSyntax, "`{` expected")
+	}
+	scope := p.templates[len(p.templates)-1]
+	tpl := &template{
+		name:  name.Value,
+		pos:   name.Start,
+		body:  t.Pos(),
+		base:  base,
+		scope: snapshot(p.templates),
+	}
+	p.skipBlock()
+	if _, ok := scope[name.Value]; ok {
+		return newError(t, name.Start, CodeTemplate,
+			"template `%s` already defined", name.Value)
+	}
+	scope[name.Value] = tpl
+
+	return nil
+}
+
+// snapshot copies template scopes, so templates declared later, including
+// the one being declared, are not visible in the template body and
+// template can not inherit itself.
+func snapshot(scopes []map[string]*template) []map[string]*template {
+	res := make([]map[string]*template, len(scopes))
+	for i, scope := range scopes {
+		res[i] = make(map[string]*template, len(scope))
+		for n, tpl := range scope {
+			res[i][n] = tpl
+		}
+	}
+
+	return res
+}
+
+// parseBase parses template name which follows the colon token in block
+// or template declaration. Token which follows the template name is
+// returned too.
+func (p *parser) parseBase() (*template, *Token, error) {
+	t := p.t
+	n, err := p.next()
+	if err != nil {
+		return nil, nil, err
+	}
+	if n.Name != NameIdent {
+		return nil, nil, newError(t, n.Start, CodeSyntax,
+			"template name expected")
+	}
+	var tpl *template
+	for i := len(p.templates) - 1; i >= 0 && tpl == nil; i-- {
+		tpl = p.templates[i][n.Value]
+	}
+	if tpl == nil {
+		var names []string
+		for _, scope := range p.templates {
+			for name := range scope {
+				names = append(names, name)
 			}
 		}
+		sort.Strings(names)
+		sg := suggest(n.Value, names)
+		e := newError(t, n.Start, CodeTemplate,
+			"unknown template: %s%s", n.Value, didYouMean(sg))
+		e.Suggestions = sg
+		return nil, nil, e
 	}
-	for _, s := range spec.Blocks {
-		if s.Require {
-			i := contains(len(blocks), func(i int) bool {
-				return blocks[i].Name == s.Name
-			})
-			if i == -1 {
-				return nil, newError(t.Line(),
-					"missing required block `%s`",
-					s.Name)
+	tpl.used = true
+	op, err := p.next()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tpl, op, nil
+}
+
+// checkUnused reports templates of the current scope which are never
+// used. Template body is checked against specification of the inheriting
+// block only, so unused template is reported the same way as unsupported
+// property is: as an error in strict block or as a warning otherwise.
+func (p *parser) checkUnused(spec *BlockSpec) error {
+	var unused []*template
+	for _, tpl := range p.templates[len(p.templates)-1] {
+		if !tpl.used {
+			unused = append(unused, tpl)
+		}
+	}
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].pos.Offset < unused[j].pos.Offset
+	})
+	for _, tpl := range unused {
+		if spec.Strict {
+			err := p.fail(newError(p.t, tpl.pos, CodeTemplate,
+				"template `%s` is never used", tpl.name))
+			if err != nil {
+				return err
 			}
+		} else {
+			p.warn(tpl.pos, CodeTemplate, nil,
+				"unused template ignored: %s", tpl.name)
 		}
 	}
 
-	return &Block{Name: name, Properties: props, Blocks: blocks}, nil
+	return nil
+}
+
+// next returns the next token or error if input is over.
+func (p *parser) next() (*Token, error) {
+	if !p.t.HasNext() {
+		return nil, newError(p.t, p.t.Pos(), CodeSyntax,
+			"unexpected EOF")
+	}
+
+	return p.t.Next()
+}
+
+// expand parses body of the template using specification of the block
+// which inherits it. Names of properties with invalid values are
+// returned too, see parseBody.
+func (p *parser) expand(tpl *template, name string,
+	spec *BlockSpec) (*Block, []string, error) {
+
+	t, templates := p.t, p.templates
+	defer func() {
+		p.t, p.templates = t, templates
+	}()
+
+	var props []*Property
+	var blocks []*Block
+	var invalid []string
+	if tpl.base != nil {
+		b, inv, err := p.expand(tpl.base, name, spec)
+		if err != nil {
+			return nil, nil, err
+		}
+		props, blocks, invalid = b.Properties, b.Blocks, inv
+	}
+	p.t, p.templates = t.at(tpl.body), tpl.scope
+	b, inv, err := p.parseBody(name, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	props, blocks = override(props, blocks, b.Properties, b.Blocks)
+
+	return &Block{Name: name, Properties: props, Blocks: blocks},
+		append(invalid, inv...), nil
+}
+
+// override merges inherited properties and blocks with the ones defined
+// in the block itself. Defined properties replace all inherited
+// properties with the same name and defined blocks replace inherited
+// blocks with the same name and labels.
+func override(baseProps []*Property, baseBlocks []*Block,
+	props []*Property, blocks []*Block) ([]*Property, []*Block) {
+
+	var ps []*Property
+	for _, bp := range baseProps {
+		if property(props, bp.Name) == nil {
+			ps = append(ps, bp)
+		}
+	}
+	ps = append(ps, props...)
+
+	var bs []*Block
+	for _, bb := range baseBlocks {
+		i := contains(len(blocks), func(i int) bool {
+			return blocks[i].Name == bb.Name &&
+				equalLabels(blocks[i].Labels, bb.Labels)
+		})
+		if i == -1 {
+			bs = append(bs, bb)
+		}
+	}
+	bs = append(bs, blocks...)
+
+	return ps, bs
+}
+
+// parseList parses comma-separated list of strings which starts with
+// the already read v token.
+func (p *parser) parseList(v *Token) ([]string, error) {
+	t := p.t
+	// TODO: Add empty list support.
+	if v.Name != NameString {
+		return nil, newError(t, v.Start, CodeType,
+			"strings list expected")
+	}
+	var lst []string
+
+	lst = append(lst, v.Value)
+	for {
+		if !t.HasNext() {
+			break
+		}
+		tk, err := t.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tk.Name != NameComma {
+			t.Unread()
+			break
+		}
+		if !t.HasNext() {
+			return nil, newError(t, t.Pos(), CodeSyntax,
+				"unexpected EOF")
+		}
+		tk, err = t.Next()
+		if err != nil {
+			return nil, err
+		}
+		if tk.Name != NameString {
+			return nil, newError(t, tk.Start, CodeType,
+				"string value expected")
+		}
+		lst = append(lst, tk.Value)
+	}
+
+	return lst, nil
+}
+
+// skipList skips the rest of comma-separated list value.
+func (p *parser) skipList() {
+	for p.t.HasNext() {
+		tk, err := p.t.Next()
+		if err != nil || tk.Name != NameComma {
+			if err == nil {
+				p.t.Unread()
+			}
+			return
+		}
+		if p.t.HasNext() {
+			p.t.Next()
+		}
+	}
+}
+
+// skipBlock skips the rest of the block including all nested blocks.
+func (p *parser) skipBlock() {
+	depth := 1
+	for depth > 0 && p.t.HasNext() {
+		tk, err := p.t.Next()
+		if err != nil {
+			continue
+		}
+		if tk.Name == NameBlockStart {
+			depth++
+		} else if tk.Name == NameBlockEnd {
+			depth--
+		}
+	}
+}
+
+// sync skips tokens of the given line up to the `;` separator, so
+// parsing can be resumed at the next property or block after syntax
+// error.
+func (p *parser) sync(line int) {
+	for p.t.HasNext() {
+		tk, err := p.t.Next()
+		if err != nil {
+			continue
+		}
+		if tk.Start.Line != line || p.t.separated() ||
+			tk.Name == NameBlockEnd {
+
+			p.t.Unread()
+			return
+		}
+		if tk.Name == NameBlockStart {
+			p.skipBlock()
+		}
+	}
+}
+
+// parseValue converts single value token into value of the given type.
+func parseValue(typ Type, v *Token) (any, error) {
+	switch typ {
+	case TypeBool:
+		if v.Name == NameIdent && v.Value == "true" {
+			return true, nil
+		} else if v.Name == NameIdent && v.Value == "false" {
+			return false, nil
+		} else {
+			return nil, errors.New("invalid boolean value")
+		}
+	case TypeDuration:
+		if v.Name != NameIdent {
+			return nil, errors.New("duration value expected")
+		}
+		d, err := time.ParseDuration(v.Value)
+		if err != nil {
+			return nil, errors.New("invalid duration value")
+		}
+		return d, nil
+	case TypeInt:
+		if v.Name != NameIdent {
+			return nil, errors.New("integer value expected")
+		}
+		i, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return nil, errors.New("invalid integer value")
+		}
+		return i, nil
+	case TypeString:
+		if v.Name != NameString {
+			return nil, errors.New("string value expected")
+		}
+		return v.Value, nil
+	default:
+		panic("unsupported Type")
+	}
+}
+
+// parseText converts unquoted textual representation of the value, like
+// the one given in command line or environment, into value of the given
+// type. String list items are separated with commas. Values of other
+// types are tokenized as configuration file values are, so the same
+// values are accepted, e.g. integers without a sign only.
+func parseText(typ Type, s string) (any, error) {
+	switch typ {
+	case TypeString:
+		return s, nil
+	case TypeStringList:
+		var lst []string
+		for _, v := range strings.Split(s, ",") {
+			lst = append(lst, strings.TrimSpace(v))
+		}
+		return lst, nil
+	default:
+		t := NewTokenizer(s)
+		if !t.HasNext() {
+			return nil, errors.New("value expected")
+		}
+		v, err := t.Next()
+		if err != nil {
+			return nil, errors.New(err.(*ParseError).Msg)
+		}
+		if t.HasNext() {
+			return nil, errors.New("single value expected")
+		}
+		return parseValue(typ, v)
+	}
+}
+
+// convertText converts textual property value and validates it the same
+// way configuration file value is validated, with Enum and custom Parser.
+func convertText(spec *PropertySpec, s string) (any, error) {
+	v, err := parseText(spec.Type, s)
+	if err != nil {
+		return nil, err
+	}
+	if bad := checkEnum(spec, v); bad != "" {
+		return nil, fmt.Errorf("invalid value `%s`, expected one of: %s%s",
+			bad, strings.Join(spec.Enum, ", "),
+			didYouMean(suggest(bad, spec.Enum)))
+	}
+	if spec.Parser != nil {
+		return spec.Parser(v)
+	}
+
+	return v, nil
 }
 
 func contains(len int, f func(int) bool) int {
@@ -336,37 +1097,161 @@ func contains(len int, f func(int) bool) int {
 	return -1
 }
 
+// checkEnum returns the first value which is not allowed by the property
+// Enum list, or empty string if all values are allowed.
+func checkEnum(spec *PropertySpec, val any) string {
+	if len(spec.Enum) == 0 {
+		return ""
+	}
+	var vals []string
+	switch v := val.(type) {
+	case string:
+		vals = []string{v}
+	case []string:
+		vals = v
+	}
+	for _, v := range vals {
+		ok := false
+		for _, e := range spec.Enum {
+			if v == e {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return v
+		}
+	}
+
+	return ""
+}
+
+func propertyNames(specs []*PropertySpec) []string {
+	var names []string
+	for _, s := range specs {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+func blockNames(specs []*BlockSpec) []string {
+	var names []string
+	for _, s := range specs {
+		names = append(names, s.Name)
+	}
+
+	return names
+}
+
+// canonical returns canonical name of the property or block defined by
+// the n token. Name is replaced with the spec name if alias is used.
+// Deprecation warning is reported for aliases and deprecated names.
+func (p *parser) canonical(kind string, n *Token, name string,
+	aliases []string, deprecated string) string {
+
+	if contains(len(aliases), func(i int) bool {
+		return aliases[i] == n.Value
+	}) != -1 {
+		p.warn(n.Start, CodeDeprecated, []string{name},
+			"%s `%s` is deprecated, use `%s` instead",
+			kind, n.Value, name)
+		return name
+	}
+	if deprecated != "" {
+		p.warn(n.Start, CodeDeprecated, nil,
+			"%s `%s` is deprecated: %s", kind, n.Value, deprecated)
+	}
+
+	return n.Value
+}
+
 func findProperty(specs []*PropertySpec, name string) *PropertySpec {
 	var ps *PropertySpec
 
 	for _, s := range specs {
-		if matchName(name, s.Name) {
+		if MatchName(name, s.Name) {
 			if ps == nil || s.Name > ps.Name {
 				ps = s
 			}
 		}
 	}
+	if ps != nil {
+		return ps
+	}
+	for _, s := range specs {
+		for _, a := range s.Aliases {
+			if a == name {
+				return s
+			}
+		}
+	}
 
-	return ps
+	return nil
 }
 
 func findBlock(specs []*BlockSpec, name string) *BlockSpec {
 	var bs *BlockSpec
 
 	for _, s := range specs {
-		if matchName(name, s.Name) {
+		if MatchName(name, s.Name) {
 			if bs == nil || s.Name > bs.Name {
 				bs = s
 			}
 		}
 	}
+	if bs != nil {
+		return bs
+	}
+	for _, s := range specs {
+		for _, a := range s.Aliases {
+			if a == name {
+				return s
+			}
+		}
+	}
 
-	return bs
+	return nil
 }
 
-func matchName(s string, pattern string) bool {
-	pattern = "^" + strings.ReplaceAll(pattern, "*", ".*") + "$"
-	p := regexp.MustCompile(pattern)
+func equalLabels(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// blockTitle returns block name followed by its quoted labels as they
+// are written in the configuration file.
+func blockTitle(name string, labels []string) string {
+	for _, l := range labels {
+		name += " " + quote(l)
+	}
+
+	return name
+}
+
+// MatchName reports whether property or block name matches the name
+// pattern used in the specification. Star in the pattern matches any
+// sequence of characters.
+func MatchName(s string, pattern string) bool {
+	p := regexp.MustCompile(namePattern(pattern))
 
 	return p.MatchString(s)
 }
+
+// namePattern converts star-pattern name into regular expression.
+func namePattern(pattern string) string {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	return "^" + strings.Join(parts, ".*") + "$"
+}
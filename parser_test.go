@@ -17,14 +17,14 @@ func TestParse(t *testing.T) {
 			"name = \"foo\", \"bar\", \"baz\"",
 			&Spec{
 				[]*PropertySpec{
-					&PropertySpec{TypeStringList, "name", false, false, nil},
+					&PropertySpec{Type: TypeStringList, Name: "name", Repeat: false, Require: false},
 				},
 				nil,
 				true,
 			},
 			&Config{
 				[]*Property{
-					&Property{TypeStringList, "name", []string{"foo", "bar", "baz"}},
+					&Property{Type: TypeStringList, Name: "name", Value: []string{"foo", "bar", "baz"}},
 				},
 				nil,
 			},
@@ -33,16 +33,16 @@ func TestParse(t *testing.T) {
 			"foo = 1; bar = 2;",
 			&Spec{
 				[]*PropertySpec{
-					&PropertySpec{TypeInt, "foo", false, true, nil},
-					&PropertySpec{TypeInt, "bar", false, true, nil},
+					&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: true},
+					&PropertySpec{Type: TypeInt, Name: "bar", Repeat: false, Require: true},
 				},
 				nil,
 				true,
 			},
 			&Config{
 				[]*Property{
-					&Property{TypeInt, "foo", 1},
-					&Property{TypeInt, "bar", 2},
+					&Property{Type: TypeInt, Name: "foo", Value: 1},
+					&Property{Type: TypeInt, Name: "bar", Value: 2},
 				},
 				nil,
 			},
@@ -51,16 +51,16 @@ func TestParse(t *testing.T) {
 			"foo = 123\nbar = \"value\"",
 			&Spec{
 				[]*PropertySpec{
-					&PropertySpec{TypeInt, "foo", false, true, nil},
-					&PropertySpec{TypeString, "bar", false, true, nil},
+					&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: true},
+					&PropertySpec{Type: TypeString, Name: "bar", Repeat: false, Require: true},
 				},
 				nil,
 				true,
 			},
 			&Config{
 				[]*Property{
-					&Property{TypeInt, "foo", 123},
-					&Property{TypeString, "bar", "value"},
+					&Property{Type: TypeInt, Name: "foo", Value: 123},
+					&Property{Type: TypeString, Name: "bar", Value: "value"},
 				},
 				nil,
 			},
@@ -69,35 +69,35 @@ func TestParse(t *testing.T) {
 			"foo = 1; bar { baz = 2; qux = 3; }",
 			&Spec{
 				[]*PropertySpec{
-					&PropertySpec{TypeInt, "foo", false, true, nil},
+					&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: true},
 				},
 				[]*BlockSpec{
 					&BlockSpec{
-						"bar",
-						false,
-						false,
-						[]*PropertySpec{
-							&PropertySpec{TypeInt, "baz", false, true, nil},
-							&PropertySpec{TypeInt, "qux", false, true, nil},
+						Name:    "bar",
+						Repeat:  false,
+						Require: false,
+						Properties: []*PropertySpec{
+							&PropertySpec{Type: TypeInt, Name: "baz", Repeat: false, Require: true},
+							&PropertySpec{Type: TypeInt, Name: "qux", Repeat: false, Require: true},
 						},
-						nil,
-						true,
+						Blocks: nil,
+						Strict: true,
 					},
 				},
 				true,
 			},
 			&Config{
 				[]*Property{
-					&Property{TypeInt, "foo", 1},
+					&Property{Type: TypeInt, Name: "foo", Value: 1},
 				},
 				[]*Block{
 					&Block{
-						"bar",
-						[]*Property{
-							&Property{TypeInt, "baz", 2},
-							&Property{TypeInt, "qux", 3},
+						Name: "bar",
+						Properties: []*Property{
+							&Property{Type: TypeInt, Name: "baz", Value: 2},
+							&Property{Type: TypeInt, Name: "qux", Value: 3},
 						},
-						nil,
+						Blocks: nil,
 					},
 				},
 			},
@@ -108,46 +108,46 @@ func TestParse(t *testing.T) {
 				nil,
 				[]*BlockSpec{
 					&BlockSpec{
-						"foo",
-						false,
-						false,
-						[]*PropertySpec{
-							&PropertySpec{TypeInt, "foo-prop", false, true, nil},
+						Name:    "foo",
+						Repeat:  false,
+						Require: false,
+						Properties: []*PropertySpec{
+							&PropertySpec{Type: TypeInt, Name: "foo-prop", Repeat: false, Require: true},
 						},
-						[]*BlockSpec{
+						Blocks: []*BlockSpec{
 							&BlockSpec{
-								"bar",
-								false,
-								false,
-								[]*PropertySpec{
-									&PropertySpec{TypeInt, "bar-prop", false, true, nil},
+								Name:    "bar",
+								Repeat:  false,
+								Require: false,
+								Properties: []*PropertySpec{
+									&PropertySpec{Type: TypeInt, Name: "bar-prop", Repeat: false, Require: true},
 								},
-								[]*BlockSpec{
+								Blocks: []*BlockSpec{
 									&BlockSpec{
-										"baz",
-										false,
-										false,
-										[]*PropertySpec{
-											&PropertySpec{TypeInt, "baz-prop", false, true, nil},
+										Name:    "baz",
+										Repeat:  false,
+										Require: false,
+										Properties: []*PropertySpec{
+											&PropertySpec{Type: TypeInt, Name: "baz-prop", Repeat: false, Require: true},
 										},
-										nil,
-										true,
+										Blocks: nil,
+										Strict: true,
 									},
 									&BlockSpec{
-										"qux",
-										false,
-										false,
-										[]*PropertySpec{
-											&PropertySpec{TypeInt, "qux-prop", false, true, nil},
+										Name:    "qux",
+										Repeat:  false,
+										Require: false,
+										Properties: []*PropertySpec{
+											&PropertySpec{Type: TypeInt, Name: "qux-prop", Repeat: false, Require: true},
 										},
-										nil,
-										true,
+										Blocks: nil,
+										Strict: true,
 									},
 								},
-								true,
+								Strict: true,
 							},
 						},
-						true,
+						Strict: true,
 					},
 				},
 				true,
@@ -156,30 +156,30 @@ func TestParse(t *testing.T) {
 				nil,
 				[]*Block{
 					&Block{
-						"foo",
-						[]*Property{
-							&Property{TypeInt, "foo-prop", 1},
+						Name: "foo",
+						Properties: []*Property{
+							&Property{Type: TypeInt, Name: "foo-prop", Value: 1},
 						},
-						[]*Block{
+						Blocks: []*Block{
 							&Block{
-								"bar",
-								[]*Property{
-									&Property{TypeInt, "bar-prop", 2},
+								Name: "bar",
+								Properties: []*Property{
+									&Property{Type: TypeInt, Name: "bar-prop", Value: 2},
 								},
-								[]*Block{
+								Blocks: []*Block{
 									&Block{
-										"baz",
-										[]*Property{
-											&Property{TypeInt, "baz-prop", 3},
+										Name: "baz",
+										Properties: []*Property{
+											&Property{Type: TypeInt, Name: "baz-prop", Value: 3},
 										},
-										nil,
+										Blocks: nil,
 									},
 									&Block{
-										"qux",
-										[]*Property{
-											&Property{TypeInt, "qux-prop", 4},
+										Name: "qux",
+										Properties: []*Property{
+											&Property{Type: TypeInt, Name: "qux-prop", Value: 4},
 										},
-										nil,
+										Blocks: nil,
 									},
 								},
 							},
@@ -201,15 +201,15 @@ func TestParseRepeatProperty(t *testing.T) {
 		"foo = 1; foo = 2;",
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo", true, false, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo", Repeat: true, Require: false},
 			},
 			nil,
 			true,
 		},
 		&Config{
 			[]*Property{
-				&Property{TypeInt, "foo", 1},
-				&Property{TypeInt, "foo", 2},
+				&Property{Type: TypeInt, Name: "foo", Value: 1},
+				&Property{Type: TypeInt, Name: "foo", Value: 2},
 			},
 			nil,
 		},
@@ -219,7 +219,7 @@ func TestParseRepeatProperty(t *testing.T) {
 	_, err := Parse(
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo", false, false, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: false},
 			},
 			nil,
 			true,
@@ -236,7 +236,7 @@ func TestParseRequireProperty(t *testing.T) {
 	cfg, err := Parse(
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo", false, false, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: false},
 			},
 			nil,
 			true,
@@ -254,7 +254,7 @@ func TestParseRequireProperty(t *testing.T) {
 	cfg, err = Parse(
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo", false, true, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: true},
 			},
 			nil,
 			true,
@@ -271,12 +271,12 @@ func TestParseStarProperty(t *testing.T) {
 		"",
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo",
-					false, false, nil},
-				&PropertySpec{TypeInt, "foo.*",
-					false, false, nil},
-				&PropertySpec{TypeInt, "foo.bar.*",
-					false, false, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo",
+					Repeat: false, Require: false},
+				&PropertySpec{Type: TypeInt, Name: "foo.*",
+					Repeat: false, Require: false},
+				&PropertySpec{Type: TypeInt, Name: "foo.bar.*",
+					Repeat: false, Require: false},
 			},
 			nil,
 			true,
@@ -291,21 +291,21 @@ func TestParseStarProperty(t *testing.T) {
 		"foo = 1; foo.baz = true; foo.bar.baz = \"str\";",
 		&Spec{
 			[]*PropertySpec{
-				&PropertySpec{TypeInt, "foo",
-					false, false, nil},
-				&PropertySpec{TypeBool, "foo.*",
-					false, false, nil},
-				&PropertySpec{TypeString, "foo.bar*",
-					false, false, nil},
+				&PropertySpec{Type: TypeInt, Name: "foo",
+					Repeat: false, Require: false},
+				&PropertySpec{Type: TypeBool, Name: "foo.*",
+					Repeat: false, Require: false},
+				&PropertySpec{Type: TypeString, Name: "foo.bar*",
+					Repeat: false, Require: false},
 			},
 			nil,
 			true,
 		},
 		&Config{
 			[]*Property{
-				&Property{TypeInt, "foo", 1},
-				&Property{TypeBool, "foo.baz", true},
-				&Property{TypeString, "foo.bar.baz", "str"},
+				&Property{Type: TypeInt, Name: "foo", Value: 1},
+				&Property{Type: TypeBool, Name: "foo.baz", Value: true},
+				&Property{Type: TypeString, Name: "foo.bar.baz", Value: "str"},
 			},
 			nil,
 		},
@@ -319,15 +319,15 @@ func TestParseRepeatBlock(t *testing.T) {
 		&Spec{
 			nil,
 			[]*BlockSpec{
-				&BlockSpec{"foo", true, true, nil, nil, true},
+				&BlockSpec{Name: "foo", Repeat: true, Require: true, Properties: nil, Blocks: nil, Strict: true},
 			},
 			true,
 		},
 		&Config{
 			nil,
 			[]*Block{
-				&Block{"foo", nil, nil},
-				&Block{"foo", nil, nil},
+				&Block{Name: "foo", Properties: nil, Blocks: nil},
+				&Block{Name: "foo", Properties: nil, Blocks: nil},
 			},
 		},
 	)
@@ -337,7 +337,7 @@ func TestParseRepeatBlock(t *testing.T) {
 		&Spec{
 			nil,
 			[]*BlockSpec{
-				&BlockSpec{"foo", false, true, nil, nil, true},
+				&BlockSpec{Name: "foo", Repeat: false, Require: true, Properties: nil, Blocks: nil, Strict: true},
 			},
 			true,
 		},
@@ -354,7 +354,7 @@ func TestParseRequireBlock(t *testing.T) {
 		&Spec{
 			nil,
 			[]*BlockSpec{
-				&BlockSpec{"foo", false, false, nil, nil, true},
+				&BlockSpec{Name: "foo", Repeat: false, Require: false, Properties: nil, Blocks: nil, Strict: true},
 			},
 			true,
 		},
@@ -372,7 +372,7 @@ func TestParseRequireBlock(t *testing.T) {
 		&Spec{
 			nil,
 			[]*BlockSpec{
-				&BlockSpec{"foo", false, true, nil, nil, true},
+				&BlockSpec{Name: "foo", Repeat: false, Require: true, Properties: nil, Blocks: nil, Strict: true},
 			},
 			true,
 		},
@@ -389,14 +389,14 @@ func TestParseStarBlock(t *testing.T) {
 			nil,
 			[]*BlockSpec{
 				&BlockSpec{
-					"*",
-					true,
-					false,
-					[]*PropertySpec{
-						&PropertySpec{TypeInt, "prop", false, false, nil},
+					Name:    "*",
+					Repeat:  true,
+					Require: false,
+					Properties: []*PropertySpec{
+						&PropertySpec{Type: TypeInt, Name: "prop", Repeat: false, Require: false},
 					},
-					nil,
-					true,
+					Blocks: nil,
+					Strict: true,
 				},
 			},
 			true,
@@ -410,29 +410,29 @@ func TestParseStarBlock(t *testing.T) {
 		nil,
 		[]*Block{
 			&Block{
-				"foo",
-				[]*Property{
-					&Property{TypeInt, "prop", 1},
+				Name: "foo",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "prop", Value: 1},
 				},
-				nil,
+				Blocks: nil,
 			},
 			&Block{
-				"bar",
-				[]*Property{
-					&Property{TypeInt, "prop", 2},
+				Name: "bar",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "prop", Value: 2},
 				},
-				nil,
+				Blocks: nil,
 			},
 			&Block{
-				"baz",
-				[]*Property{
-					&Property{TypeInt, "prop", 3},
+				Name: "baz",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "prop", Value: 3},
 				},
-				nil,
+				Blocks: nil,
 			},
 		},
 	}
-	if !reflect.DeepEqual(exp, cfg) {
+	if !reflect.DeepEqual(exp, stripPos(cfg)) {
 		t.Fatal(cfg)
 	}
 }
@@ -440,7 +440,7 @@ func TestParseStarBlock(t *testing.T) {
 func TestParsePropertyType(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeString, "foo", false, false, nil},
+			&PropertySpec{Type: TypeString, Name: "foo", Repeat: false, Require: false},
 		},
 		nil,
 		true,
@@ -462,8 +462,8 @@ func TestParsePropertyType(t *testing.T) {
 func TestParseString(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeString, "foo", false, false, nil},
-			&PropertySpec{TypeString, "bar", true, false, nil},
+			&PropertySpec{Type: TypeString, Name: "foo", Repeat: false, Require: false},
+			&PropertySpec{Type: TypeString, Name: "bar", Repeat: true, Require: false},
 		},
 		nil,
 		true,
@@ -471,9 +471,9 @@ func TestParseString(t *testing.T) {
 
 	cfg := testParse(t, `bar = "one"; foo = "two"; bar = "three"`, spec,
 		&Config{[]*Property{
-			&Property{TypeString, "bar", "one"},
-			&Property{TypeString, "foo", "two"},
-			&Property{TypeString, "bar", "three"},
+			&Property{Type: TypeString, Name: "bar", Value: "one"},
+			&Property{Type: TypeString, Name: "foo", Value: "two"},
+			&Property{Type: TypeString, Name: "bar", Value: "three"},
 		}, nil})
 	assert(t, "two", cfg.String("foo"))
 	assert(t, []string{"one", "three"}, cfg.Strings("bar"))
@@ -482,17 +482,17 @@ func TestParseString(t *testing.T) {
 func TestParseBool(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeBool, "foo", false, false, nil},
-			&PropertySpec{TypeBool, "bar", true, false, nil},
+			&PropertySpec{Type: TypeBool, Name: "foo", Repeat: false, Require: false},
+			&PropertySpec{Type: TypeBool, Name: "bar", Repeat: true, Require: false},
 		},
 		nil,
 		true,
 	}
 	testParse(t, "bar = true; foo = true; bar = false", spec,
 		&Config{[]*Property{
-			&Property{TypeBool, "bar", true},
-			&Property{TypeBool, "foo", true},
-			&Property{TypeBool, "bar", false},
+			&Property{Type: TypeBool, Name: "bar", Value: true},
+			&Property{Type: TypeBool, Name: "foo", Value: true},
+			&Property{Type: TypeBool, Name: "bar", Value: false},
 		}, nil})
 	_, err := Parse(spec, "foo = bar")
 	if err == nil || err.Error() != "1: invalid boolean value" {
@@ -503,26 +503,26 @@ func TestParseBool(t *testing.T) {
 func TestParseDuration(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeDuration, "foo", false, false, nil},
+			&PropertySpec{Type: TypeDuration, Name: "foo", Repeat: false, Require: false},
 		},
 		nil,
 		true,
 	}
 	d, _ := time.ParseDuration("1s")
 	testParse(t, "foo = 1s", spec,
-		&Config{[]*Property{&Property{TypeDuration, "foo", d}}, nil})
+		&Config{[]*Property{&Property{Type: TypeDuration, Name: "foo", Value: d}}, nil})
 	d, _ = time.ParseDuration("1h30m")
 	testParse(t, "foo = 1h30m", spec,
-		&Config{[]*Property{&Property{TypeDuration, "foo", d}}, nil})
+		&Config{[]*Property{&Property{Type: TypeDuration, Name: "foo", Value: d}}, nil})
 	d, _ = time.ParseDuration("1.5m")
 	testParse(t, "foo = 1.5m", spec,
-		&Config{[]*Property{&Property{TypeDuration, "foo", d}}, nil})
+		&Config{[]*Property{&Property{Type: TypeDuration, Name: "foo", Value: d}}, nil})
 }
 
 func TestParseComment(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeDuration, "heartbeat-ttl", true, true, nil},
+			&PropertySpec{Type: TypeDuration, Name: "heartbeat-ttl", Repeat: true, Require: true},
 		},
 		nil,
 		true,
@@ -530,15 +530,15 @@ func TestParseComment(t *testing.T) {
 	s := "heartbeat-ttl = 3s\n\n# comment\nheartbeat-ttl = 6s # more comment\n"
 	testParse(t, s, spec,
 		&Config{[]*Property{
-			&Property{TypeDuration, "heartbeat-ttl", time.Second * 3},
-			&Property{TypeDuration, "heartbeat-ttl", time.Second * 6},
+			&Property{Type: TypeDuration, Name: "heartbeat-ttl", Value: time.Second * 3},
+			&Property{Type: TypeDuration, Name: "heartbeat-ttl", Value: time.Second * 6},
 		},
 			nil})
 
 	s = "heartbeat-ttl = 3s\n\n# block {\n#}\n"
 	testParse(t, s, spec,
 		&Config{[]*Property{
-			&Property{TypeDuration, "heartbeat-ttl", time.Second * 3},
+			&Property{Type: TypeDuration, Name: "heartbeat-ttl", Value: time.Second * 3},
 		},
 			nil})
 }
@@ -546,7 +546,7 @@ func TestParseComment(t *testing.T) {
 func TestParseStrict(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeInt, "foo", false, false, nil},
+			&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: false},
 		},
 		nil,
 		true,
@@ -561,7 +561,7 @@ func TestParseStrict(t *testing.T) {
 func TestParseNonStrict(t *testing.T) {
 	spec := &Spec{
 		[]*PropertySpec{
-			&PropertySpec{TypeInt, "foo", false, false, nil},
+			&PropertySpec{Type: TypeInt, Name: "foo", Repeat: false, Require: false},
 		},
 		nil,
 		false,
@@ -572,6 +572,289 @@ func TestParseNonStrict(t *testing.T) {
 	}
 }
 
+func TestParseNonStrictBlock(t *testing.T) {
+	spec := &Spec{
+		[]*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "foo"},
+		},
+		[]*BlockSpec{
+			&BlockSpec{Name: "bar"},
+		},
+		false,
+	}
+	var ws []string
+	s := "baz { qux { foo = 1 } quux = \"}\" }\nfoo = 2\nbar {}"
+	testParse(t, s, spec, &Config{
+		[]*Property{
+			&Property{Type: TypeInt, Name: "foo", Value: 2},
+		},
+		[]*Block{
+			&Block{Name: "bar", Properties: nil, Blocks: nil},
+		},
+	})
+	_, err := Parse(spec, s, OnWarning(func(w *Warning) {
+		ws = append(ws, w.String())
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, []string{
+		"1: unsupported block ignored: baz (did you mean `bar`?)",
+	}, ws)
+
+	spec.Strict = true
+	_, err = Parse(spec, s)
+	exp := "1: unsupported block: baz (did you mean `bar`?)"
+	if err == nil || err.Error() != exp {
+		t.Fatal(err)
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	spec := &Spec{
+		[]*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "max-connections",
+				Aliases: []string{"max-conn"}},
+			&PropertySpec{Type: TypeInt, Name: "workers",
+				Deprecated: "it is computed automatically"},
+		},
+		[]*BlockSpec{
+			&BlockSpec{Name: "database", Aliases: []string{"db"}},
+		},
+		true,
+	}
+	var ws []string
+	cfg, err := Parse(spec, "max-conn = 10\nworkers = 2\ndb {}",
+		OnWarning(func(w *Warning) {
+			assert(t, CodeDeprecated, w.Code)
+			ws = append(ws, w.String())
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, &Config{
+		[]*Property{
+			&Property{Type: TypeInt, Name: "max-connections", Value: 10},
+			&Property{Type: TypeInt, Name: "workers", Value: 2},
+		},
+		[]*Block{
+			&Block{Name: "database", Properties: nil, Blocks: nil},
+		},
+	}, stripPos(cfg))
+	assert(t, []string{
+		"1: property `max-conn` is deprecated, use `max-connections` instead",
+		"2: property `workers` is deprecated: it is computed automatically",
+		"3: block `db` is deprecated, use `database` instead",
+	}, ws)
+
+	_, err = Parse(spec, "max-conn = 10\nmax-connections = 20")
+	exp := "2: property `max-connections` already defined"
+	if err == nil || err.Error() != exp {
+		t.Fatal(err)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	spec := &Spec{
+		nil,
+		[]*BlockSpec{
+			&BlockSpec{
+				Name:   "upstream",
+				Labels: []string{"name"},
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+				},
+			},
+			&BlockSpec{Name: "route", Labels: []string{"method", "path"}},
+			&BlockSpec{Name: "log"},
+		},
+		true,
+	}
+	testParse(t, `
+                upstream "backend-1" {
+                    host = "a"
+                }
+                upstream "backend.2" { host = "b" }
+                route "GET" "/api v1" {}
+        `, spec, &Config{
+		nil,
+		[]*Block{
+			&Block{
+				Name: "upstream",
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "host", Value: "a"},
+				},
+				Labels: []string{"backend-1"},
+			},
+			&Block{
+				Name: "upstream",
+				Properties: []*Property{
+					&Property{Type: TypeString, Name: "host", Value: "b"},
+				},
+				Labels: []string{"backend.2"},
+			},
+			&Block{
+				Name:   "route",
+				Labels: []string{"GET", "/api v1"},
+			},
+		},
+	})
+
+	for _, c := range []struct {
+		input string
+		err   string
+	}{
+		{"upstream {}", "1: block `upstream` expects 1 label(s): name"},
+		{"route \"GET\" {}",
+			"1: block `route` expects 2 label(s): method, path"},
+		{"log \"main\" {}", "1: block `log` does not accept labels"},
+		{"upstream \"a\" {}\nupstream \"a\" {}",
+			"2: block `upstream \"a\"` already defined"},
+		{"upstream \"a\" = 1", "1: `{` expected"},
+	} {
+		_, err := Parse(spec, c.input)
+		if err == nil || err.Error() != c.err {
+			t.Fatal(c.input, err)
+		}
+	}
+}
+
+func TestParseTemplates(t *testing.T) {
+	spec := &Spec{
+		nil,
+		[]*BlockSpec{
+			&BlockSpec{
+				Name:   "*",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "dev",
+						Require: true},
+					&PropertySpec{Type: TypeInt, Name: "size"},
+					&PropertySpec{Type: TypeString, Name: "opt",
+						Repeat: true},
+				},
+				Blocks: []*BlockSpec{
+					&BlockSpec{Name: "cache", Properties: []*PropertySpec{
+						&PropertySpec{Type: TypeInt, Name: "size"},
+					}},
+				},
+			},
+		},
+		true,
+	}
+	testParse(t, `
+                template disk {
+                    size = 10
+                    opt = "rw"
+                    opt = "noatime"
+                    cache { size = 1 }
+                }
+                template big : disk {
+                    size = 100
+                }
+                sda : disk {
+                    dev = "/dev/sda"
+                }
+                sdb : big {
+                    dev = "/dev/sdb"
+                    opt = "ro"
+                    cache { size = 2 }
+                }
+        `, spec, &Config{
+		nil,
+		[]*Block{
+			&Block{
+				Name: "sda",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "size", Value: 10},
+					&Property{Type: TypeString, Name: "opt", Value: "rw"},
+					&Property{Type: TypeString, Name: "opt", Value: "noatime"},
+					&Property{Type: TypeString, Name: "dev", Value: "/dev/sda"},
+				},
+				Blocks: []*Block{
+					&Block{
+						Name: "cache",
+						Properties: []*Property{
+							&Property{Type: TypeInt, Name: "size", Value: 1},
+						},
+					},
+				},
+			},
+			&Block{
+				Name: "sdb",
+				Properties: []*Property{
+					&Property{Type: TypeInt, Name: "size", Value: 100},
+					&Property{Type: TypeString, Name: "dev", Value: "/dev/sdb"},
+					&Property{Type: TypeString, Name: "opt", Value: "ro"},
+				},
+				Blocks: []*Block{
+					&Block{
+						Name: "cache",
+						Properties: []*Property{
+							&Property{Type: TypeInt, Name: "size", Value: 2},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	for _, c := range []struct {
+		input string
+		err   string
+	}{
+		{"template d { dev = \"x\" }\nsda : disk {}",
+			"2: unknown template: disk"},
+		{"template disk { dev = \"x\" }\nsda : dsik {}",
+			"2: unknown template: dsik (did you mean `disk`?)"},
+		{"template d {}\ntemplate d {}",
+			"2: template `d` already defined"},
+		{"template d { size = 1 }\nsda : d {}",
+			"2: missing required property `dev`"},
+		{"template d {\n    size = x\n}\nsda : d { dev = \"x\" }",
+			"2: invalid integer value"},
+		{"sda { dev = \"x\" }\nsdb : sda {}",
+			"2: unknown template: sda"},
+		{"template d {}\nsda : d = 1", "2: `{` expected"},
+		{"template d { size = x }\nsda { dev = \"x\" }",
+			"1: template `d` is never used"},
+		{"template d {\n    cache : d {}\n}\nsda : d { dev = \"x\" }",
+			"2: unknown template: d"},
+		{"template a {}\ntemplate b : a {\n    cache : b {}\n}\n" +
+			"sda : b { dev = \"x\" }",
+			"3: unknown template: b (did you mean `a`?)"},
+	} {
+		_, err := Parse(spec, c.input)
+		if err == nil || err.Error() != c.err {
+			t.Fatal(c.input, err)
+		}
+	}
+
+	// Problems in the template body are reported once.
+	s := "template d {\n" +
+		"    size = x\n" +
+		"    old = 1\n" +
+		"}\n" +
+		"sda : d { dev = \"a\" }\n" +
+		"sdb : d { dev = \"b\" }\n" +
+		"sdc : d { dev = \"c\" }\n" +
+		"template u {}\n"
+	spec.Blocks[0].Properties = append(spec.Blocks[0].Properties,
+		&PropertySpec{Type: TypeInt, Name: "old", Deprecated: "unused"})
+	spec.Strict = false
+	var ws []string
+	_, err := Parse(spec, s, CollectErrors(), OnWarning(func(w *Warning) {
+		ws = append(ws, w.String())
+	}))
+	if err == nil || err.Error() != "2: invalid integer value" {
+		t.Fatal(err)
+	}
+	assert(t, []string{
+		"3: property `old` is deprecated: unused",
+		"8: unused template ignored: u",
+	}, ws)
+}
+
 func TestParseParser(t *testing.T) {
 	parser := func(v any) (any, error) {
 		s := v.(string)
@@ -627,11 +910,29 @@ func testParse(t *testing.T, s string, spec *Spec, exp *Config) *Config {
 	if err != nil {
 		t.Fatal(err)
 	}
-	assert(t, exp, act)
+	assert(t, exp, stripPos(act))
 
 	return act
 }
 
+// stripPos resets positions of all properties and blocks of the
+// configuration, so it can be compared with expected one.
+func stripPos(c *Config) *Config {
+	var strip func(props []*Property, blocks []*Block)
+	strip = func(props []*Property, blocks []*Block) {
+		for _, p := range props {
+			p.Pos = Position{}
+		}
+		for _, b := range blocks {
+			b.Pos = Position{}
+			strip(b.Properties, b.Blocks)
+		}
+	}
+	strip(c.Properties, c.Blocks)
+
+	return c
+}
+
 func assert(t *testing.T, exp any, act any) {
 	if !reflect.DeepEqual(exp, act) {
 		t.Fatalf("%+v != %+v", exp, act)
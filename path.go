@@ -0,0 +1,260 @@
+package config
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is returned when property path can not be parsed.
+var ErrInvalidPath = errors.New("invalid path")
+
+// resolve returns all properties addressed by the path relative to the
+// node. Since names are allowed to contain separators, the whole path is
+// tried as a property name if nothing is found in nested blocks. Names
+// can be quoted to avoid ambiguity, see pathName.
+func resolve(n Node, path string) ([]*Property, error) {
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if quoted {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quoted = false
+			}
+			continue
+		}
+		if c == '"' {
+			quoted = true
+			continue
+		}
+		if c != '.' && c != '/' {
+			continue
+		}
+		bs, err := matchBlocks(n, path[:i])
+		if err != nil {
+			return nil, err
+		}
+		var ps []*Property
+		for _, b := range bs {
+			bps, err := resolve(b, path[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			ps = append(ps, bps...)
+		}
+		if len(ps) > 0 {
+			return ps, nil
+		}
+	}
+
+	if ps := n.AllProperties(path); len(ps) > 0 {
+		return ps, nil
+	}
+	if quoted {
+		return nil, &PropertyError{path, ErrInvalidPath}
+	}
+	e, err := parseElem(path)
+	if err != nil {
+		return nil, err
+	}
+	if e.labels != nil {
+		// Properties have no labels.
+		return nil, nil
+	}
+	var ps []*Property
+	for _, p := range nodeProperties(n) {
+		if e.match(p.Name) {
+			ps = append(ps, p)
+		}
+	}
+
+	return pick(ps, e.idx), nil
+}
+
+// matchBlocks returns child blocks of the node addressed by the single
+// path element.
+func matchBlocks(n Node, elem string) ([]*Block, error) {
+	if bs := n.AllBlocks(elem); len(bs) > 0 {
+		return bs, nil
+	}
+
+	e, err := parseElem(elem)
+	if err != nil {
+		return nil, err
+	}
+	var bs []*Block
+	for _, b := range nodeBlocks(n) {
+		if e.match(b.Name) &&
+			(e.labels == nil || equalLabels(b.Labels, e.labels)) {
+
+			bs = append(bs, b)
+		}
+	}
+
+	return pick(bs, e.idx), nil
+}
+
+// pathElem is a parsed path element like `upstream["backend-1"][0]`.
+type pathElem struct {
+	name   string
+	labels []string
+	// Zero-based index or -1 if element has no index.
+	idx int
+	// Name is quoted, so star is not a wildcard.
+	literal bool
+}
+
+func (e *pathElem) match(name string) bool {
+	if e.literal {
+		return name == e.name
+	}
+
+	return matchPath(name, e.name)
+}
+
+// parseElem splits path element into name, labels and index parts.
+func parseElem(elem string) (*pathElem, error) {
+	if strings.HasPrefix(elem, "\"") {
+		q, err := strconv.QuotedPrefix(elem)
+		if err != nil {
+			return nil, &PropertyError{elem, ErrInvalidPath}
+		}
+		name, _ := strconv.Unquote(q)
+		labels, idx, ok := parseSubscripts(elem[len(q):])
+		if !ok {
+			return nil, &PropertyError{elem, ErrInvalidPath}
+		}
+		return &pathElem{name, labels, idx, true}, nil
+	}
+	if !strings.HasSuffix(elem, "]") {
+		return &pathElem{elem, nil, -1, false}, nil
+	}
+	// Name can contain brackets too, so every bracket is tried as
+	// the beginning of subscripts.
+	for i := 0; i < len(elem); i++ {
+		if elem[i] != '[' {
+			continue
+		}
+		labels, idx, ok := parseSubscripts(elem[i:])
+		if ok {
+			return &pathElem{elem[:i], labels, idx, false}, nil
+		}
+	}
+
+	return nil, &PropertyError{elem, ErrInvalidPath}
+}
+
+// parseSubscripts parses sequence of quoted labels in brackets followed
+// by optional index, like `["a"]["b"][1]`.
+func parseSubscripts(s string) ([]string, int, bool) {
+	var labels []string
+	idx := -1
+
+	for s != "" {
+		if idx != -1 || s[0] != '[' {
+			return nil, 0, false
+		}
+		s = s[1:]
+		if strings.HasPrefix(s, "\"") {
+			q, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, 0, false
+			}
+			l, err := strconv.Unquote(q)
+			if err != nil {
+				return nil, 0, false
+			}
+			labels = append(labels, l)
+			s = s[len(q):]
+		} else {
+			j := strings.Index(s, "]")
+			if j == -1 {
+				return nil, 0, false
+			}
+			n, err := strconv.Atoi(s[:j])
+			if err != nil || n < 0 {
+				return nil, 0, false
+			}
+			idx = n
+			s = s[j:]
+		}
+		if !strings.HasPrefix(s, "]") {
+			return nil, 0, false
+		}
+		s = s[1:]
+	}
+
+	return labels, idx, true
+}
+
+// pathName returns the name as path element. Names which contain path
+// syntax characters are quoted, like `"db.port"`, so the path addresses
+// the name unambiguously.
+func pathName(name string) string {
+	if strings.ContainsAny(name, "./*[\"") {
+		return strconv.Quote(name)
+	}
+
+	return name
+}
+
+func matchPath(name string, elem string) bool {
+	if !strings.Contains(elem, "*") {
+		return name == elem
+	}
+	parts := strings.Split(elem, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$").
+		MatchString(name)
+}
+
+func pick[T any](items []T, idx int) []T {
+	if idx == -1 {
+		return items
+	}
+	if idx >= len(items) {
+		return nil
+	}
+
+	return items[idx : idx+1]
+}
+
+func nodeProperties(n Node) []*Property {
+	switch n := n.(type) {
+	case *Config:
+		return n.Properties
+	case *Block:
+		return n.Properties
+	default:
+		return nil
+	}
+}
+
+func nodeBlocks(n Node) []*Block {
+	switch n := n.(type) {
+	case *Config:
+		return n.Blocks
+	case *Block:
+		return n.Blocks
+	default:
+		return nil
+	}
+}
+
+// Lookup returns value of the property addressed by the path.
+// See Get for the path syntax.
+func (c *Config) Lookup(path string) (any, error) {
+	return Get[any](c, path)
+}
+
+// Lookup returns value of the property addressed by the path relative
+// to the block. See Get for the path syntax.
+func (b *Block) Lookup(path string) (any, error) {
+	return Get[any](b, path)
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookupPath(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "a.b"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "server",
+				Blocks: []*BlockSpec{
+					&BlockSpec{
+						Name: "tls",
+						Properties: []*PropertySpec{
+							&PropertySpec{Type: TypeString, Name: "cert"},
+						},
+					},
+				},
+			},
+			&BlockSpec{
+				Name:   "upstream",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+					&PropertySpec{Type: TypeInt, Name: "port",
+						Repeat: true},
+				},
+			},
+			&BlockSpec{
+				Name: "sd*",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeInt, Name: "size"},
+				},
+			},
+		},
+	}
+	cfg, err := Parse(spec, `
+                a.b = 1
+                server {
+                    tls {
+                        cert = "server.pem"
+                    }
+                }
+                upstream {
+                    host = "first"
+                    port = 80
+                    port = 8080
+                }
+                upstream {
+                    host = "second"
+                }
+                sda {
+                    size = 10
+                }
+                sdb {
+                    size = 20
+                }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := cfg.Lookup("server.tls.cert")
+	assert(t, nil, err)
+	assert(t, "server.pem", v)
+	v, err = cfg.Lookup("server/tls/cert")
+	assert(t, nil, err)
+	assert(t, "server.pem", v)
+	v, err = cfg.Lookup("a.b")
+	assert(t, nil, err)
+	assert(t, 1, v)
+	v, err = cfg.Block("server").Lookup("tls.cert")
+	assert(t, nil, err)
+	assert(t, "server.pem", v)
+
+	assert(t, "first", must(Get[string](cfg, "upstream.host")))
+	assert(t, "second", must(Get[string](cfg, "upstream[1].host")))
+	assert(t, 8080, must(Get[int](cfg, "upstream[0].port[1]")))
+	assert(t, []int{80, 8080}, must(GetAll[int](cfg, "upstream.port")))
+	assert(t, []int{10, 20}, must(GetAll[int](cfg, "sd*.size")))
+	assert(t, 20, must(Get[int](cfg, "sd*[1].size")))
+	assert(t, 42, must(GetOr(cfg, "a.c", 42)))
+	assert(t, 1, must(Get[int](cfg, `"a.b"`)))
+	assert(t, "server.pem", must(Get[string](cfg, `"server"."tls".cert`)))
+
+	// Typed accessors take exact names, like Has does.
+	assert(t, false, cfg.Has("upstream.port"))
+	assert(t, 42, cfg.IntOr("upstream.port", 42))
+	assert(t, 1, cfg.Int("a.b"))
+	if _, err := cfg.LookupInt("sd*.size"); !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+
+	_, err = cfg.Lookup("upstream[2].host")
+	if !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+	_, err = cfg.Lookup("server.ssl.cert")
+	if !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+	_, err = cfg.Lookup("upstream[x].host")
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Fatal(err)
+	}
+	_, err = Get[int](cfg, "server.tls.cert")
+	if !errors.Is(err, ErrType) {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupLabels(t *testing.T) {
+	spec := &Spec{
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name:   "upstream",
+				Labels: []string{"name"},
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+				},
+			},
+		},
+	}
+	cfg, err := Parse(spec, `
+                upstream "backend-1" { host = "a" }
+                upstream "api.v2/eu" { host = "b" }
+        `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert(t, "b", cfg.BlockLabeled("upstream", "api.v2/eu").String("host"))
+	if cfg.BlockLabeled("upstream", "backend-2") != nil {
+		t.Fatal()
+	}
+	assert(t, "a", must(Get[string](cfg, `upstream["backend-1"].host`)))
+	assert(t, "b", must(Get[string](cfg, `upstream["api.v2/eu"].host`)))
+	assert(t, "b", must(Get[string](cfg, `upstream[1].host`)))
+	_, err = cfg.Lookup(`upstream["backend-2"].host`)
+	if !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+	_, err = cfg.Lookup(`upstream["backend-1"][1].host`)
+	if !errors.Is(err, ErrNotDefined) {
+		t.Fatal(err)
+	}
+	_, err = cfg.Lookup(`upstream["backend-1].host`)
+	if !errors.Is(err, ErrInvalidPath) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Holder holds configuration snapshot read from the file and replaces it
+// when the file is reloaded. Snapshot is swapped atomically, so readers
+// never see half-parsed or invalid configuration and can use it from
+// many goroutines. Snapshots are shared and must not be modified.
+type Holder struct {
+	spec *Spec
+	file string
+	opts []Option
+	cfg  atomic.Value
+	// Serializes reloads and protects callbacks.
+	mu      sync.Mutex
+	onError func(error)
+	subs    []subscription
+	// Protects lastErr separately, so it can be read from callbacks.
+	errMu   sync.Mutex
+	lastErr error
+}
+
+type subscription struct {
+	pattern string
+	f       func(old *Config, new *Config)
+}
+
+// NewHolder parses the configuration file and returns holder with the
+// parsed configuration. Options are used for every reload.
+func NewHolder(spec *Spec, file string, opts ...Option) (*Holder, error) {
+	cfg, err := ParseFile(spec, file, opts...)
+	if err != nil {
+		return nil, err
+	}
+	h := &Holder{spec: spec, file: file, opts: opts}
+	h.cfg.Store(cfg)
+
+	return h, nil
+}
+
+// Config returns the current configuration snapshot.
+func (h *Holder) Config() *Config {
+	return h.cfg.Load().(*Config)
+}
+
+// OnError sets callback which is called when background reload, like
+// the one started by Watch, fails.
+func (h *Holder) OnError(f func(error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = f
+}
+
+// Reload parses the configuration file again and replaces the current
+// snapshot. If the file can not be read or is invalid the current
+// snapshot is kept and the error is returned.
+func (h *Holder) Reload() error {
+	h.mu.Lock()
+	notify, err := h.reload()
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	notify()
+
+	return nil
+}
+
+// OnChange registers callback which is called after reload if any
+// property or block which path matches the pattern is changed, see
+// Change.Path. Star in the pattern matches any sequence of characters,
+// e.g. "db.*" matches all properties of the db block, while "db" matches
+// only when the whole block is added or removed. Callbacks are called in
+// registration order.
+func (h *Holder) OnChange(pattern string, f func(old *Config, new *Config)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs = append(h.subs, subscription{pattern, f})
+}
+
+// LastError returns error of the last reload or nil if the last reload
+// succeeded.
+func (h *Holder) LastError() error {
+	h.errMu.Lock()
+	defer h.errMu.Unlock()
+
+	return h.lastErr
+}
+
+// reload parses the configuration file and replaces the snapshot. It must
+// be called with h.mu locked. Returned function calls OnChange callbacks
+// and must be called after h.mu is unlocked, so callbacks can use the
+// holder.
+func (h *Holder) reload() (func(), error) {
+	cfg, err := ParseFile(h.spec, h.file, h.opts...)
+	h.errMu.Lock()
+	h.lastErr = err
+	h.errMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	old := h.Config()
+	h.cfg.Store(cfg)
+
+	var fs []func(old *Config, new *Config)
+	if len(h.subs) > 0 {
+		changes := Diff(old, cfg)
+		for _, s := range h.subs {
+			for _, c := range changes {
+				if matchPath(c.Path, s.pattern) {
+					fs = append(fs, s.f)
+					break
+				}
+			}
+		}
+	}
+
+	return func() {
+		for _, f := range fs {
+			f(old, cfg)
+		}
+	}, nil
+}
+
+// report calls the OnError callback with the error.
+func (h *Holder) report(err error) {
+	h.mu.Lock()
+	f := h.onError
+	h.mu.Unlock()
+	if f != nil {
+		f(err)
+	}
+}
+
+// Watcher reloads configuration when the file changes.
+type Watcher struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Watch starts polling the configuration file every interval and reloads
+// configuration when modification time or size of the file changes.
+// Reload is postponed until the file stays unchanged for one interval,
+// so file which is being written is not read. Errors are reported to the
+// OnError callback. Only the file itself is watched since configuration
+// language has no include directive.
+func (h *Holder) Watch(interval time.Duration) (*Watcher, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval: %s", interval)
+	}
+	w := &Watcher{stop: make(chan struct{}), done: make(chan struct{})}
+	fi, lastErr := os.Stat(h.file)
+	pending := false
+
+	go func() {
+		defer close(w.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-t.C:
+			}
+			nfi, err := os.Stat(h.file)
+			if err != nil {
+				// Report the same problem only once.
+				if lastErr == nil || lastErr.Error() != err.Error() {
+					h.report(err)
+				}
+				lastErr = err
+				continue
+			}
+			changed := lastErr != nil || fi == nil ||
+				!nfi.ModTime().Equal(fi.ModTime()) ||
+				nfi.Size() != fi.Size()
+			fi, lastErr = nfi, nil
+			if changed {
+				pending = true
+			} else if pending {
+				pending = false
+				if err := h.Reload(); err != nil {
+					h.report(err)
+				}
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// Stop stops watching and waits for the watcher goroutine to exit. It is
+// safe to call Stop more than once.
+func (w *Watcher) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// ReloadOnSignal reloads configuration every time one of the signals is
+// received. If no signals are given SIGHUP is used, so other signals,
+// like SIGINT, keep their default behavior. Result of every reload is
+// reported to the callback, with nil error on success, if callback is
+// not nil. Returned function stops signal handling, it is safe to call
+// it more than once.
+func (h *Holder) ReloadOnSignal(f func(error),
+	sigs ...os.Signal) (stop func()) {
+
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+
+	go func() {
+		defer close(exited)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+			}
+			err := h.Reload()
+			if f != nil {
+				f(err)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+		<-exited
+	}
+}
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var reloadSpec = &Spec{
+	Properties: []*PropertySpec{
+		&PropertySpec{Type: TypeInt, Name: "port", Require: true},
+	},
+}
+
+// writeFile atomically replaces the file content and sets its
+// modification time.
+func writeFile(t *testing.T, file string, s string, mtime time.Time) {
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, []byte(s), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tmp, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor polls the condition until it is true or timeout expires.
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHolderReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.conf")
+	now := time.Now()
+	writeFile(t, file, "port = 80", now)
+	h, err := NewHolder(reloadSpec, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, 80, h.Config().Int("port"))
+
+	writeFile(t, file, "port = 8080", now)
+	if err := h.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, 8080, h.Config().Int("port"))
+
+	writeFile(t, file, "port = x", now)
+	if err := h.Reload(); err == nil {
+		t.Fatal("invalid configuration accepted")
+	}
+	assert(t, 8080, h.Config().Int("port"))
+
+	_, err = NewHolder(reloadSpec, filepath.Join(t.TempDir(), "none"))
+	if err == nil {
+		t.Fatal("missing file accepted")
+	}
+}
+
+func TestHolderWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.conf")
+	now := time.Now()
+	writeFile(t, file, "port = 80", now)
+	h, err := NewHolder(reloadSpec, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan error, 10)
+	h.OnError(func(err error) {
+		errs <- err
+	})
+	w, err := h.Watch(time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+
+	writeFile(t, file, "port = 8080", now.Add(time.Second))
+	waitFor(t, func() bool {
+		return h.Config().Int("port") == 8080
+	})
+
+	writeFile(t, file, "port = x", now.Add(2*time.Second))
+	select {
+	case err := <-errs:
+		assert(t, file+":1: invalid integer value", err.Error())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+	}
+	assert(t, 8080, h.Config().Int("port"))
+	w.Stop()
+
+	_, err = h.Watch(0)
+	if err == nil || err.Error() != "invalid watch interval: 0s" {
+		t.Fatal(err)
+	}
+}
+
+func TestHolderOnChange(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port"},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name: "db",
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "host"},
+				},
+			},
+		},
+	}
+	file := filepath.Join(t.TempDir(), "app.conf")
+	now := time.Now()
+	writeFile(t, file, "port = 80\ndb { host = \"a\" }", now)
+	h, err := NewHolder(spec, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	h.OnChange("db.*", func(old *Config, new *Config) {
+		o, _ := GetOr(old, "db.host", "")
+		n, _ := GetOr(new, "db.host", "")
+		calls = append(calls, "db: "+o+" -> "+n)
+	})
+	h.OnChange("db", func(old *Config, new *Config) {
+		calls = append(calls, "db")
+	})
+	h.OnChange("port", func(old *Config, new *Config) {
+		calls = append(calls, "port")
+		// Callbacks can use the holder.
+		h.OnError(nil)
+		assert(t, new, h.Config())
+	})
+
+	writeFile(t, file, "port = 8080\ndb { host = \"a\" }", now)
+	if err := h.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "port = 8080\ndb { host = \"b\" }", now)
+	if err := h.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, file, "port = 8080", now)
+	if err := h.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	// "db" matches only when the whole block is added or removed.
+	assert(t, []string{"port", "db: a -> b", "db: b -> ", "db"}, calls)
+}
+
+func TestHolderReloadOnSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported")
+	}
+	file := filepath.Join(t.TempDir(), "app.conf")
+	now := time.Now()
+	writeFile(t, file, "port = 80", now)
+	h, err := NewHolder(reloadSpec, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := make(chan error)
+	// SIGHUP is used by default.
+	stop := h.ReloadOnSignal(func(err error) {
+		results <- err
+	})
+	defer stop()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reload := func() error {
+		if err := p.Signal(syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-results:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+		return nil
+	}
+
+	writeFile(t, file, "port = 8080", now)
+	if err := reload(); err != nil {
+		t.Fatal(err)
+	}
+	assert(t, 8080, h.Config().Int("port"))
+	assert(t, nil, h.LastError())
+
+	writeFile(t, file, "port = x", now)
+	if err := reload(); err == nil {
+		t.Fatal("invalid configuration accepted")
+	}
+	assert(t, 8080, h.Config().Int("port"))
+	if h.LastError() == nil {
+		t.Fatal("last error is not set")
+	}
+	stop()
+}
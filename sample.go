@@ -0,0 +1,133 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	sampleIndent   = "    "
+	sampleStarName = "example"
+)
+
+// Sample generates annotated configuration file template for the given
+// specification. Required properties are filled with example values or
+// placeholders, optional properties and blocks are commented out.
+// Deprecated properties and blocks are omitted.
+// Star-names are shown with an example name instead of the star.
+func Sample(spec *Spec) string {
+	var sb strings.Builder
+
+	sampleBlock(&sb, 0, false, spec.Properties, spec.Blocks)
+
+	return sb.String()
+}
+
+func sampleBlock(sb *strings.Builder, depth int, commented bool,
+	props []*PropertySpec, blocks []*BlockSpec) {
+
+	first := true
+	sep := func() {
+		if !first {
+			sb.WriteString("\n")
+		}
+		first = false
+	}
+
+	for _, p := range props {
+		if p.Deprecated != "" {
+			continue
+		}
+		sep()
+		attrs := []string{p.Type.String()}
+		if p.Require {
+			attrs = append(attrs, "required")
+		}
+		if p.Repeat {
+			attrs = append(attrs, "repeat")
+		}
+		sampleComment(sb, depth, commented, p.Name+": "+
+			strings.Join(attrs, ", "))
+		if p.Description != "" {
+			sampleComment(sb, depth, commented, p.Description)
+		}
+		if len(p.Enum) > 0 {
+			sampleComment(sb, depth, commented, "Values: "+
+				strings.Join(p.Enum, ", "))
+		}
+		var v string
+		if p.Default != nil {
+			v = formatValue(p.Type, p.Default)
+		} else if p.Example != "" {
+			v = p.Example
+		} else if len(p.Enum) > 0 {
+			v = quote(p.Enum[0])
+		} else {
+			v = samplePlaceholder(p.Type)
+		}
+		line := sampleName(p.Name) + " = " + v
+		if !p.Require && !commented {
+			line = "#" + line
+		}
+		sampleLine(sb, depth, commented, line)
+	}
+
+	for _, b := range blocks {
+		if b.Deprecated != "" {
+			continue
+		}
+		sep()
+		c := commented || !b.Require
+		attrs := []string{"block"}
+		if b.Require {
+			attrs = append(attrs, "required")
+		}
+		if b.Repeat {
+			attrs = append(attrs, "repeat")
+		}
+		sampleComment(sb, depth, commented, b.Name+": "+
+			strings.Join(attrs, ", "))
+		if b.Description != "" {
+			sampleComment(sb, depth, commented, b.Description)
+		}
+		// Label names are used as example label values.
+		sampleLine(sb, depth, c,
+			blockTitle(sampleName(b.Name), b.Labels)+" {")
+		sampleBlock(sb, depth+1, c, b.Properties, b.Blocks)
+		sampleLine(sb, depth, c, "}")
+	}
+}
+
+func sampleComment(sb *strings.Builder, depth int, commented bool,
+	s string) {
+
+	for _, l := range strings.Split(s, "\n") {
+		sampleLine(sb, depth, commented, "# "+l)
+	}
+}
+
+func sampleLine(sb *strings.Builder, depth int, commented bool, s string) {
+	if commented {
+		sb.WriteString("#")
+	}
+	fmt.Fprintf(sb, "%s%s\n", strings.Repeat(sampleIndent, depth), s)
+}
+
+func sampleName(name string) string {
+	return strings.ReplaceAll(name, "*", sampleStarName)
+}
+
+func samplePlaceholder(typ Type) string {
+	switch typ {
+	case TypeBool:
+		return "false"
+	case TypeDuration:
+		return "0s"
+	case TypeInt:
+		return "0"
+	case TypeString, TypeStringList:
+		return "\"\""
+	default:
+		panic("unsupported Type")
+	}
+}
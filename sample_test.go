@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSample(t *testing.T) {
+	exp := "# port: int, required\n" +
+		"# TCP port to listen on.\n" +
+		"port = 8080\n" +
+		"\n" +
+		"# timeout: duration\n" +
+		"#timeout = 30s\n" +
+		"\n" +
+		"# db: block, required\n" +
+		"# Database connection.\n" +
+		"db {\n" +
+		"    # host: string\n" +
+		"    #host = \"localhost\"\n" +
+		"\n" +
+		"    # *: block, repeat\n" +
+		"#    example {\n" +
+		"#        # tags: stringlist, repeat\n" +
+		"#        tags = \"\"\n" +
+		"#    }\n" +
+		"}\n" +
+		"\n" +
+		"# upstream: block\n" +
+		"#upstream \"name\" {\n" +
+		"#    # url: string, required\n" +
+		"#    url = \"\"\n" +
+		"#}\n"
+	act := Sample(docSpec)
+	assert(t, exp, act)
+
+	// Generated sample must be a valid configuration.
+	if _, err := Parse(docSpec, act); err != nil {
+		t.Fatal(err)
+	}
+}
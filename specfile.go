@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+var (
+	specPropertySpec = &BlockSpec{
+		Name:   "property",
+		Repeat: true,
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeString, Name: "name", Require: true},
+			&PropertySpec{Type: TypeString, Name: "type", Require: true,
+				Parser: parseTypeName},
+			&PropertySpec{Type: TypeBool, Name: "repeat"},
+			&PropertySpec{Type: TypeBool, Name: "require"},
+			&PropertySpec{Type: TypeString, Name: "description"},
+			&PropertySpec{Type: TypeString, Name: "example"},
+			&PropertySpec{Type: TypeString, Name: "default"},
+			&PropertySpec{Type: TypeStringList, Name: "enum"},
+			&PropertySpec{Type: TypeStringList, Name: "aliases"},
+			&PropertySpec{Type: TypeString, Name: "deprecated"},
+			&PropertySpec{Type: TypeString, Name: "merge",
+				Enum: []string{"replace", "append"}},
+		},
+		Strict: true,
+	}
+	specBlockSpec = &BlockSpec{
+		Name:   "block",
+		Repeat: true,
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeString, Name: "name", Require: true},
+			&PropertySpec{Type: TypeBool, Name: "repeat"},
+			&PropertySpec{Type: TypeBool, Name: "require"},
+			&PropertySpec{Type: TypeBool, Name: "strict"},
+			&PropertySpec{Type: TypeString, Name: "description"},
+			&PropertySpec{Type: TypeString, Name: "example"},
+			&PropertySpec{Type: TypeStringList, Name: "aliases"},
+			&PropertySpec{Type: TypeString, Name: "deprecated"},
+			&PropertySpec{Type: TypeStringList, Name: "labels"},
+		},
+		Strict: true,
+	}
+	specSpec = &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeBool, Name: "strict"},
+		},
+		Blocks: []*BlockSpec{specPropertySpec, specBlockSpec},
+		Strict: true,
+	}
+)
+
+func init() {
+	// Block specs are recursive so nested blocks are assigned here
+	// to avoid initialization cycle.
+	specBlockSpec.Blocks = []*BlockSpec{specPropertySpec, specBlockSpec}
+}
+
+// LoadSpecFile reads specification file and builds Spec from it.
+func LoadSpecFile(file string) (*Spec, error) {
+	d, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadSpec(string(d))
+}
+
+// LoadSpec builds Spec from its textual description.
+//
+// Specification can be described with the same configuration language
+// instead of Go code. Specification file consists of top-level strict
+// property and a serie of property and block blocks. Block blocks can
+// be nested the same way blocks are nested in the described file.
+//
+// Example:
+// strict = true
+// property {
+//     name = "port"
+//     type = "int"
+//     require = true
+// }
+// block {
+//     name = "*"
+//     repeat = true
+//     property {
+//         name = "dev"
+//         type = "string"
+//     }
+// }
+//
+// Supported type names are: bool, duration, int, string and stringlist.
+// Both property and block can have description and example strings.
+// Property default value is given as an unquoted string, string list
+// items are separated with commas. Allowed values of string properties
+// are given with enum string list property. Old names are given with
+// aliases string list property and deprecation message with deprecated
+// property. Merge mode of repeated property is given with merge property
+// which is either "replace" (default) or "append". Block label names are
+// given with labels string list property.
+func LoadSpec(s string) (*Spec, error) {
+	cfg, err := Parse(specSpec, s)
+	if err != nil {
+		return nil, err
+	}
+
+	props, err := loadPropertySpecs(cfg.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := loadBlockSpecs(cfg.Blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Spec{
+		Properties: props,
+		Blocks:     blocks,
+		Strict:     cfg.BoolOr("strict", false),
+	}, nil
+}
+
+func loadPropertySpecs(blocks []*Block) ([]*PropertySpec, error) {
+	var specs []*PropertySpec
+
+	for _, b := range blocks {
+		if b.Name != specPropertySpec.Name {
+			continue
+		}
+		s := &PropertySpec{
+			Type:        b.Any("type").(Type),
+			Name:        b.String("name"),
+			Repeat:      b.BoolOr("repeat", false),
+			Require:     b.BoolOr("require", false),
+			Description: b.StringOr("description", ""),
+			Example:     b.StringOr("example", ""),
+			Enum:        b.StringListOr("enum", nil),
+			Aliases:     b.StringListOr("aliases", nil),
+			Deprecated:  b.StringOr("deprecated", ""),
+		}
+		if b.StringOr("merge", "") == "append" {
+			s.Merge = MergeAppend
+		}
+		if b.Has("default") {
+			v, err := parseText(s.Type, b.String("default"))
+			if err != nil {
+				return nil, fmt.Errorf("property `%s` default: %s",
+					s.Name, err)
+			}
+			s.Default = v
+		}
+		specs = append(specs, s)
+	}
+
+	return specs, nil
+}
+
+func loadBlockSpecs(blocks []*Block) ([]*BlockSpec, error) {
+	var specs []*BlockSpec
+
+	for _, b := range blocks {
+		if b.Name != specBlockSpec.Name {
+			continue
+		}
+		props, err := loadPropertySpecs(b.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err := loadBlockSpecs(b.Blocks)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, &BlockSpec{
+			Name:        b.String("name"),
+			Repeat:      b.BoolOr("repeat", false),
+			Require:     b.BoolOr("require", false),
+			Properties:  props,
+			Blocks:      blocks,
+			Strict:      b.BoolOr("strict", false),
+			Description: b.StringOr("description", ""),
+			Example:     b.StringOr("example", ""),
+			Aliases:     b.StringListOr("aliases", nil),
+			Deprecated:  b.StringOr("deprecated", ""),
+			Labels:      b.StringListOr("labels", nil),
+		})
+	}
+
+	return specs, nil
+}
+
+func parseTypeName(v any) (any, error) {
+	for t, n := range typeNames {
+		if n == v.(string) {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported type: %s", v)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadSpec(t *testing.T) {
+	s := `
+strict = true
+property {
+    name = "port"
+    type = "int"
+    require = true
+}
+property {
+    name = "hosts"
+    type = "stringlist"
+    repeat = true
+    merge = "append"
+}
+block {
+    name = "*"
+    repeat = true
+    strict = true
+    property {
+        name = "dev"
+        type = "string"
+        require = true
+    }
+    block {
+        name = "options"
+        property { name = "timeout"; type = "duration" }
+    }
+}
+`
+	spec, err := LoadSpec(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "port", Require: true},
+			&PropertySpec{Type: TypeStringList, Name: "hosts",
+				Repeat: true, Merge: MergeAppend},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{
+				Name:   "*",
+				Repeat: true,
+				Properties: []*PropertySpec{
+					&PropertySpec{Type: TypeString, Name: "dev",
+						Require: true},
+				},
+				Blocks: []*BlockSpec{
+					&BlockSpec{
+						Name: "options",
+						Properties: []*PropertySpec{
+							&PropertySpec{Type: TypeDuration,
+								Name: "timeout"},
+						},
+					},
+				},
+				Strict: true,
+			},
+		},
+		Strict: true,
+	}
+	assert(t, exp, spec)
+
+	_, err = Parse(spec, "port = 80; sda { dev = \"/dev/sda\" }")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSpecInvalid(t *testing.T) {
+	_, err := LoadSpec("property {\n name = \"foo\"\n type = \"float\"\n}")
+	if err == nil || err.Error() != "3: unsupported type: float" {
+		t.Fatal(err)
+	}
+	_, err = LoadSpec("property {\n type = \"int\"\n}")
+	if err == nil || err.Error() != "1: missing required property `name`" {
+		t.Fatal(err)
+	}
+	// Template can not inherit itself in recursive spec.
+	_, err = LoadSpec("template t {\n name = \"x\"\n block : t { }\n}\n" +
+		"block : t { }")
+	if err == nil || err.Error() != "3: unknown template: t" {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSpecDoc(t *testing.T) {
+	s := `
+property {
+    name = "hosts"
+    type = "stringlist"
+    description = "Upstream hosts."
+    default = "a, b"
+}
+block {
+    name = "db"
+    description = "Database connection."
+    example = "db { port = 5432 }"
+    property { name = "port"; type = "int"; default = "5432" }
+}
+`
+	spec, err := LoadSpec(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert(t, "Upstream hosts.", spec.Properties[0].Description)
+	assert(t, []string{"a", "b"}, spec.Properties[0].Default)
+	assert(t, "Database connection.", spec.Blocks[0].Description)
+	assert(t, "db { port = 5432 }", spec.Blocks[0].Example)
+	assert(t, 5432, spec.Blocks[0].Properties[0].Default)
+
+	_, err = LoadSpec(`property { name = "port"; type = "int"; default = "x" }`)
+	if err == nil || err.Error() !=
+		"property `port` default: invalid integer value" {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggest returns names of the given patterns which are similar to the
+// name and can be proposed as a replacement of misspelled name.
+// Star-pattern is proposed if its literal prefix is similar to the
+// beginning of the name.
+func suggest(name string, patterns []string) []string {
+	type candidate struct {
+		name string
+		dist int
+	}
+	var cs []candidate
+
+	for _, p := range patterns {
+		var d int
+		s := p
+		if i := strings.Index(p, "*"); i != -1 {
+			s = p[:i]
+			if s == "" {
+				continue
+			}
+			// Compare prefix with the beginning of the name
+			// allowing it to be one character longer or shorter.
+			r := []rune(name)
+			n := len([]rune(s))
+			d = -1
+			for l := n - 1; l <= n+1; l++ {
+				if l < 0 || l > len(r) {
+					continue
+				}
+				if dd := distance(string(r[:l]), s); d == -1 ||
+					dd < d {
+
+					d = dd
+				}
+			}
+		} else {
+			d = distance(name, s)
+		}
+		if d > 0 && d <= suggestThreshold(s) {
+			cs = append(cs, candidate{p, d})
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		return cs[i].dist < cs[j].dist
+	})
+
+	var names []string
+	for _, c := range cs {
+		names = append(names, c.name)
+	}
+
+	return names
+}
+
+// suggestThreshold returns maximum edit distance for the name to be
+// considered similar.
+func suggestThreshold(s string) int {
+	t := len([]rune(s)) / 3
+	if t < 1 {
+		t = 1
+	}
+
+	return t
+}
+
+// distance returns edit distance between two strings. Insertion,
+// deletion, substitution and transposition of two adjacent characters
+// are counted as a single edit (optimal string alignment distance).
+func distance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(minInt(d[i-1][j]+1, d[i][j-1]+1),
+				d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] &&
+				ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// didYouMean formats suggestions as an error message suffix.
+func didYouMean(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var qs []string
+	for _, n := range names {
+		qs = append(qs, "`"+n+"`")
+	}
+
+	return " (did you mean " + strings.Join(qs, " or ") + "?)"
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	assert(t, 0, distance("foo", "foo"))
+	assert(t, 1, distance("wokers", "workers"))
+	assert(t, 3, distance("", "foo"))
+	assert(t, 1, distance("ab", "ba"))
+	assert(t, 2, distance("abc", "bca"))
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"workers", "work-dir", "timeout", "log.*", "*"}
+	assert(t, []string{"workers"}, suggest("wokers", names))
+	assert(t, []string{"log.*"}, suggest("lg.level", names))
+	assert(t, []string(nil), suggest("foo", names))
+}
+
+func TestParseSuggest(t *testing.T) {
+	spec := &Spec{
+		Properties: []*PropertySpec{
+			&PropertySpec{Type: TypeInt, Name: "workers"},
+			&PropertySpec{Type: TypeString, Name: "mode",
+				Enum: []string{"fast", "safe"}},
+			&PropertySpec{Type: TypeStringList, Name: "modes",
+				Enum: []string{"fast", "safe"}},
+		},
+		Blocks: []*BlockSpec{
+			&BlockSpec{Name: "server"},
+		},
+		Strict: true,
+	}
+
+	_, err := Parse(spec, "wokers = 1")
+	exp := "1: unsupported property: wokers (did you mean `workers`?)"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	assert(t, []string{"workers"}, perr.Suggestions)
+
+	_, err = Parse(spec, "sever {}")
+	exp = "1: unsupported block: sever (did you mean `server`?)"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+
+	_, err = Parse(spec, "mode = \"fsat\"")
+	exp = "1: invalid value `fsat`, expected one of: fast, safe " +
+		"(did you mean `fast`?)"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+	_, err = Parse(spec, "modes = \"fast\", \"slow\"")
+	exp = "1: invalid value `slow`, expected one of: fast, safe"
+	if err == nil || err.Error() != exp {
+		t.Fatalf("%s != %s", exp, err)
+	}
+	testParse(t, "mode = \"safe\"", spec, &Config{
+		[]*Property{&Property{Type: TypeString, Name: "mode", Value: "safe"}},
+		nil,
+	})
+}
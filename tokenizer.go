@@ -17,29 +17,80 @@ const (
 	NameEq
 	NameIdent
 	NameString
+	NameColon
 )
 
+// Position in the input text.
+type Position struct {
+	// Line number, starting at 1.
+	Line int
+	// Column number (byte count in the line), starting at 1.
+	Column int
+	// Byte offset from the beginning of the input, starting at 0.
+	Offset int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
 type Token struct {
 	Name  Name
 	Value string
+	// Position of the first character of the token.
+	Start Position
+	// Position right after the last character of the token.
+	End Position
 }
 
 type Tokenizer struct {
+	s      string
 	r      *strings.Reader
-	line   int
+	pos    Position
+	prev   Position
 	last   *Token
 	unread bool
+	// `;` is eaten after the last token.
+	sep bool
+	// The last token is preceded by `;`.
+	lastSep bool
 }
 
 func NewTokenizer(s string) *Tokenizer {
 	return &Tokenizer{
-		r:    strings.NewReader(s),
-		line: 1,
+		s:   s,
+		r:   strings.NewReader(s),
+		pos: Position{Line: 1, Column: 1},
 	}
 }
 
+// at returns new tokenizer over the same input which starts reading at
+// the given position.
+func (t *Tokenizer) at(pos Position) *Tokenizer {
+	r := strings.NewReader(t.s)
+	r.Seek(int64(pos.Offset), io.SeekStart)
+
+	return &Tokenizer{s: t.s, r: r, pos: pos}
+}
+
 func (t *Tokenizer) Line() int {
-	return t.line
+	return t.pos.Line
+}
+
+// Pos returns current position of the tokenizer.
+func (t *Tokenizer) Pos() Position {
+	return t.pos
+}
+
+// sourceLine returns input line which contains the given byte offset.
+func (t *Tokenizer) sourceLine(off int) string {
+	start := strings.LastIndexByte(t.s[:off], '\n') + 1
+	end := strings.IndexByte(t.s[off:], '\n')
+	if end == -1 {
+		return t.s[start:]
+	}
+
+	return t.s[start : off+end]
 }
 
 func (t *Tokenizer) HasNext() bool {
@@ -60,47 +111,84 @@ func (t *Tokenizer) Next() (*Token, error) {
 
 	t.eatWS()
 
+	start := t.pos
 	var tok *Token
 	var err error
 	var r rune
-	r, _, err = t.r.ReadRune()
+	r, err = t.readRune()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(t, start, CodeSyntax, err)
 	}
 
 	if r == '}' {
-		tok, err = &Token{NameBlockEnd, "}"}, nil
+		tok, err = &Token{Name: NameBlockEnd, Value: "}"}, nil
 	} else if r == '{' {
-		tok, err = &Token{NameBlockStart, "{"}, nil
+		tok, err = &Token{Name: NameBlockStart, Value: "{"}, nil
+	} else if r == ':' {
+		tok, err = &Token{Name: NameColon, Value: ":"}, nil
 	} else if r == ',' {
-		tok, err = &Token{NameComma, ","}, nil
+		tok, err = &Token{Name: NameComma, Value: ","}, nil
 	} else if r == '=' {
-		tok, err = &Token{NameEq, "="}, nil
+		tok, err = &Token{Name: NameEq, Value: "="}, nil
 	} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
-		t.r.UnreadRune()
+		t.unreadRune()
 		id, e := t.readIdent()
-		tok, err = &Token{NameIdent, id}, e
+		tok, err = &Token{Name: NameIdent, Value: id}, e
 	} else if r == '"' {
-		t.r.UnreadRune()
+		t.unreadRune()
 		s, e := t.readString()
 
-		tok, err = &Token{NameString, s}, e
+		tok, err = &Token{Name: NameString, Value: s}, e
 	} else {
 		tok, err = nil, fmt.Errorf("unexpected `%c`", r)
 	}
 
-	if err == nil {
-		t.last = tok
+	if err != nil {
+		return nil, wrapError(t, start, CodeSyntax, err)
+	}
+	tok.Start = start
+	tok.End = t.pos
+	t.last = tok
+	t.lastSep = t.sep
+	t.sep = false
+
+	return tok, nil
+}
+
+// separated reports whether the last token read is separated from the
+// previous one with `;`.
+func (t *Tokenizer) separated() bool {
+	return t.lastSep
+}
+
+func (t *Tokenizer) readRune() (rune, error) {
+	r, size, err := t.r.ReadRune()
+	if err != nil {
+		return r, err
+	}
+	t.prev = t.pos
+	t.pos.Offset += size
+	if r == '\n' {
+		t.pos.Line++
+		t.pos.Column = 1
+	} else {
+		t.pos.Column += size
 	}
 
-	return tok, err
+	return r, nil
+}
+
+// unreadRune unreads the last rune read. Only one rune can be unread.
+func (t *Tokenizer) unreadRune() {
+	t.r.UnreadRune()
+	t.pos = t.prev
 }
 
 func (t *Tokenizer) readIdent() (string, error) {
 	id := ""
 
 	for {
-		r, _, err := t.r.ReadRune()
+		r, err := t.readRune()
 		if err == io.EOF {
 			break
 		}
@@ -108,7 +196,7 @@ func (t *Tokenizer) readIdent() (string, error) {
 			return "", err
 		}
 		if t.lexemeEnd(r) {
-			t.r.UnreadRune()
+			t.unreadRune()
 			break
 		}
 		id += string(r)
@@ -118,14 +206,14 @@ func (t *Tokenizer) readIdent() (string, error) {
 }
 
 func (t *Tokenizer) readString() (string, error) {
-	r, _, err := t.r.ReadRune()
+	r, err := t.readRune()
 	if r != '"' || err != nil {
 		return "", errors.New("`\"` character expected")
 	}
 
 	s := ""
 	for {
-		r, _, err := t.r.ReadRune()
+		r, err := t.readRune()
 		if err != nil {
 			return "", err
 		}
@@ -133,7 +221,7 @@ func (t *Tokenizer) readString() (string, error) {
 			break
 		}
 		if r == '\\' {
-			r, _, err := t.r.ReadRune()
+			r, err := t.readRune()
 			if err != nil {
 				return "", errors.New("unknown escape sequence: EOF")
 			}
@@ -157,28 +245,28 @@ func (t *Tokenizer) lexemeEnd(r rune) bool {
 // Eat all whitespaces, lexeme delimeters and comments.
 func (t *Tokenizer) eatWS() {
 	for {
-		r, _, err := t.r.ReadRune()
+		r, err := t.readRune()
 		if err != nil {
 			break
 		}
-		if r == '\n' {
-			t.line++
-		} else if r == '#' {
+		if r == '#' {
 			for {
-				r, _, err := t.r.ReadRune()
+				r, err := t.readRune()
 				if err != nil {
 					break
 				}
 				if r == '\n' {
-					t.r.UnreadRune()
+					t.unreadRune()
 					break
 				}
 
 			}
-		} else if unicode.IsSpace(r) || r == ';' {
+		} else if r == ';' {
+			t.sep = true
+		} else if unicode.IsSpace(r) {
 			// Just eat.
 		} else {
-			t.r.UnreadRune()
+			t.unreadRune()
 			break
 		}
 	}
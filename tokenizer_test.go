@@ -5,48 +5,53 @@ import (
 )
 
 func TestTokenizerString(t *testing.T) {
-	testTokensSerie(t, `""`, &Token{NameString, ""})
-	testTokensSerie(t, `" "`, &Token{NameString, " "})
-	testTokensSerie(t, `"foo bar"`, &Token{NameString, "foo bar"})
-	testTokensSerie(t, `"foo\"bar"`, &Token{NameString, "foo\"bar"})
-	testTokensSerie(t, `"\"\'\\"`, &Token{NameString, "\"'\\"})
+	testTokensSerie(t, `""`, &Token{Name: NameString, Value: ""})
+	testTokensSerie(t, `" "`, &Token{Name: NameString, Value: " "})
+	testTokensSerie(t, `"foo bar"`, &Token{Name: NameString, Value: "foo bar"})
+	testTokensSerie(t, `"foo\"bar"`, &Token{Name: NameString, Value: "foo\"bar"})
+	testTokensSerie(t, `"\"\'\\"`, &Token{Name: NameString, Value: "\"'\\"})
 }
 
 func TestTokenizer(t *testing.T) {
 	testTokensSerie(t, "foo bar baz",
-		&Token{NameIdent, "foo"},
-		&Token{NameIdent, "bar"},
-		&Token{NameIdent, "baz"})
+		&Token{Name: NameIdent, Value: "foo"},
+		&Token{Name: NameIdent, Value: "bar"},
+		&Token{Name: NameIdent, Value: "baz"})
 	testTokensSerie(t, "foo = bar",
-		&Token{NameIdent, "foo"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "bar"})
+		&Token{Name: NameIdent, Value: "foo"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "bar"})
+	testTokensSerie(t, "sdb : disk {",
+		&Token{Name: NameIdent, Value: "sdb"},
+		&Token{Name: NameColon, Value: ":"},
+		&Token{Name: NameIdent, Value: "disk"},
+		&Token{Name: NameBlockStart, Value: "{"})
 	testTokensSerie(t, "foo = 123xxx123",
-		&Token{NameIdent, "foo"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "123xxx123"})
+		&Token{Name: NameIdent, Value: "foo"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "123xxx123"})
 	testTokensSerie(t, "foo = 1, bar, \"baz\"",
-		&Token{NameIdent, "foo"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "1"},
-		&Token{NameComma, ","},
-		&Token{NameIdent, "bar"},
-		&Token{NameComma, ","},
-		&Token{NameString, "baz"})
+		&Token{Name: NameIdent, Value: "foo"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "1"},
+		&Token{Name: NameComma, Value: ","},
+		&Token{Name: NameIdent, Value: "bar"},
+		&Token{Name: NameComma, Value: ","},
+		&Token{Name: NameString, Value: "baz"})
 	testTokensSerie(t, "block {foo = 1; bar = 2;}",
-		&Token{NameIdent, "block"},
-		&Token{NameBlockStart, "{"},
-		&Token{NameIdent, "foo"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "1"},
-		&Token{NameIdent, "bar"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "2"},
-		&Token{NameBlockEnd, "}"})
+		&Token{Name: NameIdent, Value: "block"},
+		&Token{Name: NameBlockStart, Value: "{"},
+		&Token{Name: NameIdent, Value: "foo"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "1"},
+		&Token{Name: NameIdent, Value: "bar"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "2"},
+		&Token{Name: NameBlockEnd, Value: "}"})
 	testTokensSerie(t, "heartbeat-ttl = 3s\n\n# block {\n#}\n",
-		&Token{NameIdent, "heartbeat-ttl"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "3s"})
+		&Token{Name: NameIdent, Value: "heartbeat-ttl"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "3s"})
 	testTokensSerie(t, ""+
 		"# Comment line.\n"+
 		"param-a = 1\n"+
@@ -60,30 +65,59 @@ func TestTokenizer(t *testing.T) {
 		"    param-d = \"value-d\"\n"+
 		"    param-e = value-e;\n"+
 		"}",
-		&Token{NameIdent, "param-a"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "1"},
-		&Token{NameIdent, "param-b"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "2"},
-		&Token{NameIdent, "block-a"},
-		&Token{NameBlockStart, "{"},
-		&Token{NameIdent, "param-c"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "3"},
-		&Token{NameIdent, "param-d"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "4"},
-		&Token{NameBlockEnd, "}"},
-		&Token{NameIdent, "block-b"},
-		&Token{NameBlockStart, "{"},
-		&Token{NameIdent, "param-d"},
-		&Token{NameEq, "="},
-		&Token{NameString, "value-d"},
-		&Token{NameIdent, "param-e"},
-		&Token{NameEq, "="},
-		&Token{NameIdent, "value-e"},
-		&Token{NameBlockEnd, "}"})
+		&Token{Name: NameIdent, Value: "param-a"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "1"},
+		&Token{Name: NameIdent, Value: "param-b"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "2"},
+		&Token{Name: NameIdent, Value: "block-a"},
+		&Token{Name: NameBlockStart, Value: "{"},
+		&Token{Name: NameIdent, Value: "param-c"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "3"},
+		&Token{Name: NameIdent, Value: "param-d"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "4"},
+		&Token{Name: NameBlockEnd, Value: "}"},
+		&Token{Name: NameIdent, Value: "block-b"},
+		&Token{Name: NameBlockStart, Value: "{"},
+		&Token{Name: NameIdent, Value: "param-d"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameString, Value: "value-d"},
+		&Token{Name: NameIdent, Value: "param-e"},
+		&Token{Name: NameEq, Value: "="},
+		&Token{Name: NameIdent, Value: "value-e"},
+		&Token{Name: NameBlockEnd, Value: "}"})
+}
+
+func TestTokenizerPosition(t *testing.T) {
+	tk := NewTokenizer("foo = 1\n  bar {\n\"x\ny\" }")
+	exp := []*Token{
+		&Token{NameIdent, "foo", Position{1, 1, 0}, Position{1, 4, 3}},
+		&Token{NameEq, "=", Position{1, 5, 4}, Position{1, 6, 5}},
+		&Token{NameIdent, "1", Position{1, 7, 6}, Position{1, 8, 7}},
+		&Token{NameIdent, "bar", Position{2, 3, 10}, Position{2, 6, 13}},
+		&Token{NameBlockStart, "{", Position{2, 7, 14}, Position{2, 8, 15}},
+		&Token{NameString, "x\ny", Position{3, 1, 16}, Position{4, 3, 21}},
+		&Token{NameBlockEnd, "}", Position{4, 4, 22}, Position{4, 5, 23}},
+	}
+	for _, tok := range exp {
+		act, err := tk.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *act != *tok {
+			t.Fatalf("%v != %v", act, tok)
+		}
+	}
+
+	tk = NewTokenizer("foo\n  $")
+	tk.Next()
+	_, err := tk.Next()
+	if err == nil || err.(*ParseError).Column != 3 {
+		t.Fatal(err)
+	}
 }
 
 func testTokensSerie(t *testing.T, s string, toks ...*Token) {
@@ -97,7 +131,7 @@ func testTokensSerie(t *testing.T, s string, toks ...*Token) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if *act != *tok {
+		if act.Name != tok.Name || act.Value != tok.Value {
 			t.Fatalf("%v != %v", act, tok)
 		}
 		tk.Unread()
@@ -108,7 +142,7 @@ func testTokensSerie(t *testing.T, s string, toks ...*Token) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		if *act != *tok {
+		if act.Name != tok.Name || act.Value != tok.Value {
 			t.Fatalf("%v != %v", act, tok)
 		}
 	}